@@ -0,0 +1,155 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AccessTokenType is the token type ("typ") header value of JWT access
+// tokens as defined in RFC 9068, section 2.1.
+const AccessTokenType = "at+jwt"
+
+// AccessTokenClaims holds claims of JWT access token as defined in RFC 9068,
+// section 2.2. It can be embedded in a structure defining additional claims.
+type AccessTokenClaims struct {
+	Issuer         string   `json:"iss"`
+	ExpirationTime int64    `json:"exp"`
+	Audience       Audience `json:"aud"`
+	Subject        string   `json:"sub"`
+	ClientID       string   `json:"client_id"`
+	IssuedAt       int64    `json:"iat"`
+	TokenID        string   `json:"jti"`
+
+	// Scope is space separated list of granted scopes. It is required if
+	// authorization request included scope parameter.
+	Scope string `json:"scope,omitempty"`
+
+	AuthTime int64    `json:"auth_time,omitempty"`
+	ACR      string   `json:"acr,omitempty"`
+	AMR      []string `json:"amr,omitempty"`
+
+	// Confirmation binds token to the key of its sender, for example
+	// using DPoP.
+	Confirmation *Confirmation `json:"cnf,omitempty"`
+}
+
+// Confirmation is the "cnf" claim value as defined in RFC 7800, section 3.1,
+// holding proof-of-possession key information.
+type Confirmation struct {
+	// JWKThumbprint is the thumbprint of the DPoP proof key as defined
+	// in RFC 9449, section 6.1.
+	JWKThumbprint string `json:"jkt,omitempty"`
+
+	// JWK is the public proof-of-possession key.
+	JWK *JWK `json:"jwk,omitempty"`
+}
+
+// accessTokenRequiredClaims lists claims that every JWT access token must
+// contain, as defined in RFC 9068, section 2.2.
+var accessTokenRequiredClaims = []string{"iss", "exp", "aud", "sub", "client_id", "iat", "jti"}
+
+// accessTokenValidators returns validators rejecting required claims with
+// zero values. AccessTokenClaims does not omit empty fields, so that its
+// unset claims are present, but hold zero values.
+func accessTokenValidators() []claimValidator {
+	validators := make([]claimValidator, len(accessTokenRequiredClaims))
+	for i, name := range accessTokenRequiredClaims {
+		validators[i] = claimValidator{claim: name, fn: nonZeroClaim(name)}
+	}
+	return validators
+}
+
+// nonZeroClaim returns validator that fails with ErrMissingClaim if given
+// claim is not present, or is an empty string, zero number or empty array.
+func nonZeroClaim(name string) ClaimValidator {
+	return func(claims map[string]interface{}) error {
+		switch v := claims[name].(type) {
+		case nil:
+			return ErrMissingClaim
+		case string:
+			if v == "" {
+				return ErrMissingClaim
+			}
+		case float64:
+			if v == 0 {
+				return ErrMissingClaim
+			}
+		case []interface{}:
+			if len(v) == 0 {
+				return ErrMissingClaim
+			}
+		}
+		return nil
+	}
+}
+
+// EncodeAccessToken return claims serialized as signed JWT access token with
+// "at+jwt" type. Claims are usually AccessTokenClaims or a structure
+// embedding it. ClaimError is returned if any of the required claims is
+// missing or has a zero value.
+func EncodeAccessToken(sig Signer, claims interface{}) ([]byte, error) {
+	if sig.Algorithm() == "none" {
+		return nil, errors.New("access token must be signed")
+	}
+
+	b, err := json.Marshal(claims)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode claims: %s", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("claims must be a JSON object: %s", err)
+	}
+	if err := validateClaims(raw, accessTokenRequiredClaims, accessTokenValidators()); err != nil {
+		return nil, err
+	}
+	return Encode(sig, json.RawMessage(b), EncodeWithType(AccessTokenType))
+}
+
+// AccessTokenVerifier validates JWT access tokens on resource server, as
+// described by RFC 9068, section 4.
+type AccessTokenVerifier struct {
+	// Verifier checks token signature. Usually this is KeySet holding
+	// authorization server keys.
+	Verifier Verifier
+
+	// Issuer is the authorization server issuer identifier that must
+	// exactly match "iss" claim.
+	Issuer string
+
+	// Audience is resource server identifier that must be present in
+	// token audience.
+	Audience string
+
+	// Now is optional (can be nil) function returning current time. It
+	// is useful mostly for testing.
+	Now func() time.Time
+}
+
+// Verify validates access token and unpack its claims to given structure,
+// usually AccessTokenClaims or a structure embedding it. ErrInvalidType is
+// returned for tokens that are not of "at+jwt" type, so that for example ID
+// tokens cannot be used as access tokens. Required claims with zero values
+// are rejected as missing. Additional validation can be enabled using
+// options.
+func (av *AccessTokenVerifier) Verify(token []byte, claims interface{}, opts ...DecodeOption) error {
+	if av.Verifier.Algorithm() == "none" {
+		return ErrInvalidSigner
+	}
+	now := av.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	opts = append([]DecodeOption{
+		WithClock(now),
+		WithType(AccessTokenType),
+		WithRequiredClaims(accessTokenRequiredClaims...),
+		withClaimValidators(accessTokenValidators()),
+		WithIssuer(av.Issuer),
+		WithAudience(av.Audience),
+	}, opts...)
+	return DecodeClaims(token, av.Verifier, claims, opts...)
+}
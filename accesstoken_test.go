@@ -0,0 +1,126 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAccessToken(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	signer := RSA256Signer(privRSA, "as-key")
+
+	type claims struct {
+		AccessTokenClaims
+		Tenant string `json:"tenant"`
+	}
+	valid := claims{
+		AccessTokenClaims: AccessTokenClaims{
+			Issuer:         "https://as.example.com",
+			ExpirationTime: now.Add(time.Hour).Unix(),
+			Audience:       Audience{"https://rs.example.com"},
+			Subject:        "5ba552d67",
+			ClientID:       "s6BhdRkqt3",
+			IssuedAt:       now.Unix(),
+			TokenID:        "dbe39bf3a3ba4238a513f51d6e1691c4",
+			Scope:          "openid profile",
+		},
+		Tenant: "acme",
+	}
+
+	token, err := EncodeAccessToken(signer, &valid)
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+
+	av := &AccessTokenVerifier{
+		Verifier: signer,
+		Issuer:   "https://as.example.com",
+		Audience: "https://rs.example.com",
+		Now:      func() time.Time { return now },
+	}
+	var got claims
+	if err := av.Verify(token, &got, WithClaimValidator("scope", ScopeContains("profile"))); err != nil {
+		t.Fatalf("cannot verify: %s", err)
+	}
+	if got.Tenant != "acme" || got.ClientID != "s6BhdRkqt3" {
+		t.Fatalf("unexpected claims: %+v", got)
+	}
+
+	// token of different type must not be accepted as access token
+	jwt, err := Encode(signer, &valid)
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+	if err := av.Verify(jwt, &got); err != ErrInvalidType {
+		t.Fatalf("want ErrInvalidType, got %v", err)
+	}
+
+	other := *av
+	other.Audience = "https://other.example.com"
+	var cerr *ClaimError
+	if err := other.Verify(token, &got); !errors.As(err, &cerr) || cerr.Claim != "aud" {
+		t.Fatalf("want aud claim error, got %v", err)
+	}
+
+	if _, err := EncodeAccessToken(signer, map[string]interface{}{"iss": "x"}); !errors.As(err, &cerr) {
+		t.Fatalf("want claim error, got %v", err)
+	}
+	if _, err := EncodeAccessToken(noneSigner{}, &valid); err == nil {
+		t.Fatal("want error for unsigned token")
+	}
+}
+
+func TestAccessTokenZeroClaims(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	signer := RSA256Signer(privRSA, "as-key")
+	av := &AccessTokenVerifier{
+		Verifier: signer,
+		Issuer:   "https://as.example.com",
+		Audience: "https://rs.example.com",
+		Now:      func() time.Time { return now },
+	}
+
+	cases := map[string]struct {
+		claim string
+		clear func(*AccessTokenClaims)
+	}{
+		"empty issuer":          {"iss", func(c *AccessTokenClaims) { c.Issuer = "" }},
+		"zero expiration time":  {"exp", func(c *AccessTokenClaims) { c.ExpirationTime = 0 }},
+		"empty audience":        {"aud", func(c *AccessTokenClaims) { c.Audience = Audience{} }},
+		"empty subject":         {"sub", func(c *AccessTokenClaims) { c.Subject = "" }},
+		"empty client id":       {"client_id", func(c *AccessTokenClaims) { c.ClientID = "" }},
+		"zero issued at":        {"iat", func(c *AccessTokenClaims) { c.IssuedAt = 0 }},
+		"empty token id":        {"jti", func(c *AccessTokenClaims) { c.TokenID = "" }},
+		"empty audience string": {"aud", func(c *AccessTokenClaims) { c.Audience = Audience{""} }},
+	}
+
+	for tname, tc := range cases {
+		t.Run(tname, func(t *testing.T) {
+			claims := AccessTokenClaims{
+				Issuer:         "https://as.example.com",
+				ExpirationTime: now.Add(time.Hour).Unix(),
+				Audience:       Audience{"https://rs.example.com"},
+				Subject:        "5ba552d67",
+				ClientID:       "s6BhdRkqt3",
+				IssuedAt:       now.Unix(),
+				TokenID:        "dbe39bf3a3ba4238a513f51d6e1691c4",
+			}
+			tc.clear(&claims)
+
+			var cerr *ClaimError
+			if _, err := EncodeAccessToken(signer, &claims); !errors.As(err, &cerr) || cerr.Claim != tc.claim || !errors.Is(err, ErrMissingClaim) {
+				t.Fatalf("want missing %s claim error, got %v", tc.claim, err)
+			}
+
+			token, err := Encode(signer, &claims, EncodeWithType(AccessTokenType))
+			if err != nil {
+				t.Fatalf("cannot encode: %s", err)
+			}
+			var got AccessTokenClaims
+			if err := av.Verify(token, &got); !errors.As(err, &cerr) || cerr.Claim != tc.claim || !errors.Is(err, ErrMissingClaim) {
+				t.Fatalf("want missing %s claim error, got %v", tc.claim, err)
+			}
+		})
+	}
+}
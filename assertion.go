@@ -0,0 +1,160 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// ClientAssertionType is the "client_assertion_type" parameter value
+	// used for client authentication with JWT, as defined in RFC 7523,
+	// section 2.2.
+	ClientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+	// JWTBearerGrantType is the "grant_type" parameter value used for
+	// using JWT as authorization grant, as defined in RFC 7523, section
+	// 2.1.
+	JWTBearerGrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+)
+
+// AssertionClaims holds claims of JWT assertion as defined in RFC 7523,
+// section 3.
+type AssertionClaims struct {
+	Issuer         string   `json:"iss"`
+	Subject        string   `json:"sub"`
+	Audience       Audience `json:"aud"`
+	ExpirationTime int64    `json:"exp"`
+	NotBefore      int64    `json:"nbf,omitempty"`
+	IssuedAt       int64    `json:"iat"`
+	TokenID        string   `json:"jti"`
+}
+
+// NewAssertion returns JWT assertion issued by given issuer about given
+// subject, for the authorization server identified by audience, usually
+// its token endpoint URL. Assertion is valid for given lifetime, that
+// should be as short as possible.
+//
+// Use it directly to create authorization grant, or NewClientAssertion for
+// client authentication.
+func NewAssertion(sig Signer, issuer, subject, audience string, lifetime time.Duration) ([]byte, error) {
+	tokenID, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return Encode(sig, &AssertionClaims{
+		Issuer:         issuer,
+		Subject:        subject,
+		Audience:       Audience{audience},
+		ExpirationTime: now.Add(lifetime).Unix(),
+		IssuedAt:       now.Unix(),
+		TokenID:        tokenID,
+	})
+}
+
+// NewClientAssertion returns JWT assertion authenticating client at given
+// token endpoint, as described by RFC 7523, section 2.2. Use signer holding
+// client private key for "private_key_jwt" authentication method and HMAC
+// signer with client secret for "client_secret_jwt".
+func NewClientAssertion(sig Signer, clientID, tokenEndpoint string, lifetime time.Duration) ([]byte, error) {
+	return NewAssertion(sig, clientID, clientID, tokenEndpoint, lifetime)
+}
+
+// ClientAssertionVerifier validates client assertions on the authorization
+// server, as described by RFC 7523, section 3.
+type ClientAssertionVerifier struct {
+	// Audiences lists accepted audience values. Usually this is token
+	// endpoint URL and authorization server issuer identifier.
+	Audiences []string
+
+	// Keys returns verifier of the given client assertions, using
+	// client registered key or secret.
+	Keys func(clientID string) (Verifier, error)
+
+	// Store keeps track of already used assertion IDs. It is required,
+	// because assertions must not be replayed.
+	Store ReplayStore
+
+	// MaxLifetime is the maximum accepted assertion lifetime. Defaults to
+	// five minutes.
+	MaxLifetime time.Duration
+
+	// Now is optional (can be nil) function returning current time. It
+	// is useful mostly for testing.
+	Now func() time.Time
+}
+
+// Verify validates client assertion and returns its claims. If clientID is
+// not empty, for example because it was sent as "client_id" request
+// parameter, assertion must be issued by that client.
+func (cv *ClientAssertionVerifier) Verify(assertion []byte, clientID string) (*AssertionClaims, error) {
+	now := time.Now
+	if cv.Now != nil {
+		now = cv.Now
+	}
+	maxLifetime := cv.MaxLifetime
+	if maxLifetime == 0 {
+		maxLifetime = 5 * time.Minute
+	}
+	if cv.Store == nil {
+		return nil, errors.New("replay store is required")
+	}
+	if cv.Keys == nil {
+		return nil, errors.New("client keys are required")
+	}
+
+	// issuer is needed to select verification key
+	tok, err := ParseUnverified(assertion)
+	if err != nil {
+		return nil, err
+	}
+	var unverified struct {
+		Issuer string `json:"iss"`
+	}
+	if err := json.Unmarshal(tok.Claims, &unverified); err != nil {
+		return nil, fmt.Errorf("cannot JSON decode claims: %s", err)
+	}
+	if unverified.Issuer == "" {
+		return nil, &ClaimError{Claim: "iss", Err: ErrMissingClaim}
+	}
+	if clientID != "" && unverified.Issuer != clientID {
+		return nil, &ClaimError{Claim: "iss", Err: errors.New("unexpected issuer")}
+	}
+	v, err := cv.Keys(unverified.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims AssertionClaims
+	err = DecodeClaims(assertion, v, &claims,
+		WithClock(now),
+		WithRequiredClaims("iss", "sub", "aud", "exp", "jti"),
+		WithClaimValidator("aud", func(raw map[string]interface{}) error {
+			for _, a := range cv.Audiences {
+				if audienceContains(raw["aud"], a) {
+					return nil
+				}
+			}
+			return errors.New("unexpected audience")
+		}),
+		WithClaimValidator("sub", func(raw map[string]interface{}) error {
+			if raw["sub"] != raw["iss"] {
+				return errors.New("client assertion subject must be the client")
+			}
+			return nil
+		}),
+		WithClaimValidator("exp", func(raw map[string]interface{}) error {
+			exp, _ := raw["exp"].(float64)
+			if time.Unix(int64(exp), 0).Sub(now()) > maxLifetime {
+				return errors.New("assertion lifetime too long")
+			}
+			return nil
+		}),
+		WithReplayStore(cv.Store))
+	if err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
@@ -0,0 +1,110 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClientAssertion(t *testing.T) {
+	const endpoint = "https://as.example.com/token"
+	secret := HMAC256([]byte("client secret 9901283"), "")
+	keys := map[string]Signer{
+		"private-key-client": RSA256Signer(privRSA, ""),
+		"secret-client":      secret,
+	}
+
+	cv := &ClientAssertionVerifier{
+		Audiences: []string{endpoint, "https://as.example.com"},
+		Keys: func(clientID string) (Verifier, error) {
+			if k, ok := keys[clientID]; ok {
+				return k, nil
+			}
+			return nil, errors.New("unknown client")
+		},
+		Store: NewMemoryReplayStore(nil),
+	}
+
+	for clientID, sig := range keys {
+		assertion, err := NewClientAssertion(sig, clientID, endpoint, time.Minute)
+		if err != nil {
+			t.Fatalf("%s: cannot create assertion: %s", clientID, err)
+		}
+		claims, err := cv.Verify(assertion, clientID)
+		if err != nil {
+			t.Fatalf("%s: cannot verify: %s", clientID, err)
+		}
+		if claims.Subject != clientID {
+			t.Fatalf("%s: unexpected claims: %+v", clientID, claims)
+		}
+		if _, err := cv.Verify(assertion, clientID); err != ErrReplayed {
+			t.Fatalf("%s: want ErrReplayed, got %v", clientID, err)
+		}
+	}
+
+	// verifier without keys must fail instead of panicking
+	noKeys := &ClientAssertionVerifier{Audiences: cv.Audiences, Store: NewMemoryReplayStore(nil)}
+	assertion, err := NewClientAssertion(secret, "secret-client", endpoint, time.Minute)
+	if err != nil {
+		t.Fatalf("cannot create assertion: %s", err)
+	}
+	if _, err := noKeys.Verify(assertion, "secret-client"); err == nil {
+		t.Fatal("want error for verifier without keys")
+	}
+
+	cases := map[string]struct {
+		assertion func() ([]byte, error)
+		clientID  string
+		wantClaim string
+		wantErr   error
+	}{
+		"wrong-audience": {
+			assertion: func() ([]byte, error) {
+				return NewClientAssertion(secret, "secret-client", "https://other.example.com/token", time.Minute)
+			},
+			wantClaim: "aud",
+		},
+		"too-long-lifetime": {
+			assertion: func() ([]byte, error) {
+				return NewClientAssertion(secret, "secret-client", endpoint, time.Hour)
+			},
+			wantClaim: "exp",
+		},
+		"subject-not-client": {
+			assertion: func() ([]byte, error) {
+				return NewAssertion(secret, "secret-client", "alice", endpoint, time.Minute)
+			},
+			wantClaim: "sub",
+		},
+		"client-id-mismatch": {
+			assertion: func() ([]byte, error) {
+				return NewClientAssertion(secret, "secret-client", endpoint, time.Minute)
+			},
+			clientID:  "private-key-client",
+			wantClaim: "iss",
+		},
+		"wrong-key": {
+			assertion: func() ([]byte, error) {
+				return NewClientAssertion(HMAC256([]byte("guess"), ""), "secret-client", endpoint, time.Minute)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+	}
+	for tname, tc := range cases {
+		assertion, err := tc.assertion()
+		if err != nil {
+			t.Fatalf("%s: cannot create assertion: %s", tname, err)
+		}
+		_, err = cv.Verify(assertion, tc.clientID)
+		if tc.wantErr != nil {
+			if err != tc.wantErr {
+				t.Errorf("%s: want %v, got %v", tname, tc.wantErr, err)
+			}
+			continue
+		}
+		var cerr *ClaimError
+		if !errors.As(err, &cerr) || cerr.Claim != tc.wantClaim {
+			t.Errorf("%s: want %q claim error, got %v", tname, tc.wantClaim, err)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ClaimValidator is a function that validates token claims. It is called
+// only after token signature was verified. Claims are provided as decoded by
+// json.Unmarshal into map.
+type ClaimValidator func(claims map[string]interface{}) error
+
+type claimValidator struct {
+	claim string
+	fn    ClaimValidator
+}
+
+// ClaimError is returned when token claim is missing or is not passing
+// validation.
+type ClaimError struct {
+	// Claim is the name of invalid claim.
+	Claim string
+	// Err is the reason of validation failure. ErrMissingClaim for
+	// missing required claims.
+	Err error
+}
+
+func (e *ClaimError) Error() string {
+	return fmt.Sprintf("invalid %q claim: %s", e.Claim, e.Err)
+}
+
+func (e *ClaimError) Unwrap() error {
+	return e.Err
+}
+
+// ErrMissingClaim is returned wrapped in ClaimError, when required claim is
+// not present.
+var ErrMissingClaim = errors.New("missing claim")
+
+// WithRequiredClaims returns option that makes decoding fail with ClaimError
+// if any of given claims is not present or null.
+func WithRequiredClaims(names ...string) DecodeOption {
+	return func(o *decodeOptions) {
+		o.required = append(o.required, names...)
+	}
+}
+
+// WithClaimValidator returns option that makes decoding run given validator
+// function. Error returned by validator is wrapped in ClaimError, using
+// given claim name.
+func WithClaimValidator(claim string, fn ClaimValidator) DecodeOption {
+	return func(o *decodeOptions) {
+		o.validators = append(o.validators, claimValidator{claim: claim, fn: fn})
+	}
+}
+
+// withClaimValidators returns option that makes decoding run all given
+// validators.
+func withClaimValidators(validators []claimValidator) DecodeOption {
+	return func(o *decodeOptions) {
+		o.validators = append(o.validators, validators...)
+	}
+}
+
+// validateClaims runs all checks in the order they were registered, required
+// claims first.
+func validateClaims(claims map[string]interface{}, required []string, validators []claimValidator) error {
+	for _, name := range required {
+		if claims[name] == nil {
+			return &ClaimError{Claim: name, Err: ErrMissingClaim}
+		}
+	}
+	for _, v := range validators {
+		if err := v.fn(claims); err != nil {
+			return &ClaimError{Claim: v.claim, Err: err}
+		}
+	}
+	return nil
+}
+
+// ScopeContains returns validator that makes sure that "scope" claim, being
+// space separated list as defined in RFC 8693, section 4.2, contains all
+// given scopes.
+func ScopeContains(scopes ...string) ClaimValidator {
+	return func(claims map[string]interface{}) error {
+		raw, ok := claims["scope"]
+		if !ok {
+			return ErrMissingClaim
+		}
+		s, ok := raw.(string)
+		if !ok {
+			return errors.New("not a string")
+		}
+		granted := strings.Fields(s)
+	outer:
+		for _, want := range scopes {
+			for _, got := range granted {
+				if got == want {
+					continue outer
+				}
+			}
+			return fmt.Errorf("scope %q not granted", want)
+		}
+		return nil
+	}
+}
+
+// Audience is "aud" claim value as defined in RFC 7519, section 4.1.3. It is
+// serialized as a single string if it contains only one value and as an
+// array otherwise.
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return errors.New("audience must be a string or an array of strings")
+	}
+	*a = Audience(many)
+	return nil
+}
+
+// Contains returns true if given audience is present.
+func (a Audience) Contains(aud string) bool {
+	for _, s := range a {
+		if s == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// WithIssuer returns option that makes decoding fail with ClaimError unless
+// "iss" claim is equal to given issuer.
+func WithIssuer(issuer string) DecodeOption {
+	return WithClaimValidator("iss", func(claims map[string]interface{}) error {
+		raw, ok := claims["iss"]
+		if !ok {
+			return ErrMissingClaim
+		}
+		if raw != issuer {
+			return errors.New("unexpected issuer")
+		}
+		return nil
+	})
+}
+
+// WithAudience returns option that makes decoding fail with ClaimError
+// unless "aud" claim contains given audience.
+func WithAudience(audience string) DecodeOption {
+	return WithClaimValidator("aud", func(claims map[string]interface{}) error {
+		raw, ok := claims["aud"]
+		if !ok {
+			return ErrMissingClaim
+		}
+		if audienceContains(raw, audience) {
+			return nil
+		}
+		return errors.New("unexpected audience")
+	})
+}
+
+// audienceContains returns true if "aud" claim value, as decoded by
+// json.Unmarshal, contains given audience.
+func audienceContains(raw interface{}, audience string) bool {
+	switch aud := raw.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecodeClaimsValidators(t *testing.T) {
+	signer := HMAC256([]byte("top secret 5534210"), "")
+	token, err := Encode(signer, map[string]interface{}{
+		"scope":  "orders:read orders:write",
+		"tenant": "acme",
+	})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+
+	errNotAcme := errors.New("not acme")
+	isAcme := func(claims map[string]interface{}) error {
+		if claims["tenant"] != "acme" {
+			return errNotAcme
+		}
+		return nil
+	}
+
+	cases := map[string]struct {
+		opts      []DecodeOption
+		wantClaim string
+		wantErr   error
+	}{
+		"ok": {
+			opts: []DecodeOption{
+				WithRequiredClaims("scope", "tenant"),
+				WithClaimValidator("scope", ScopeContains("orders:write")),
+				WithClaimValidator("tenant", isAcme),
+			},
+		},
+		"missing-claim": {
+			opts:      []DecodeOption{WithRequiredClaims("tenant", "sub")},
+			wantClaim: "sub",
+			wantErr:   ErrMissingClaim,
+		},
+		"scope-not-granted": {
+			opts:      []DecodeOption{WithClaimValidator("scope", ScopeContains("orders:read", "admin"))},
+			wantClaim: "scope",
+		},
+		"custom-validator": {
+			opts: []DecodeOption{WithClaimValidator("tenant", func(map[string]interface{}) error {
+				return errNotAcme
+			})},
+			wantClaim: "tenant",
+			wantErr:   errNotAcme,
+		},
+	}
+
+	for tname, tc := range cases {
+		var claims map[string]interface{}
+		err := DecodeClaims(token, signer, &claims, tc.opts...)
+		if tc.wantClaim == "" {
+			if err != nil {
+				t.Errorf("%s: want no error, got %q", tname, err)
+			}
+			continue
+		}
+
+		var cerr *ClaimError
+		if !errors.As(err, &cerr) {
+			t.Errorf("%s: want ClaimError, got %q", tname, err)
+			continue
+		}
+		if cerr.Claim != tc.wantClaim {
+			t.Errorf("%s: want %q claim error, got %q", tname, tc.wantClaim, cerr.Claim)
+		}
+		if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: want %q error, got %q", tname, tc.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Conformance tests use example vectors published in JOSE specifications.
+//
+// Only algorithms supported by this package are covered. RFC 7515 appendix
+// A.3 and A.4 (ECDSA) and RFC 8037 (EdDSA) vectors are not included, because
+// neither ECDSA nor EdDSA are implemented. RFC 7518 does not define complete
+// JWS examples. From RFC 7520, HMAC and RSA PKCS #1 v1.5 examples are used.
+// There are no published JWS examples for HS384, HS512, RS384 and RS512, so
+// HMAC variants are checked against RFC 4231 vectors of the underlying
+// algorithm and RSA variants are checked against signatures computed
+// directly with crypto/rsa.
+
+// rfc7515Claims is the payload of RFC 7515 appendix A.1 and A.2 examples.
+var rfc7515Claims = map[string]interface{}{
+	"iss":                        "joe",
+	"exp":                        float64(1300819380),
+	"http://example.com/is_root": true,
+}
+
+// rfc7515Clock returns time at which RFC 7515 examples are not expired.
+func rfc7515Clock() time.Time {
+	return time.Unix(1300819000, 0)
+}
+
+func TestConformanceRFC7515HS256(t *testing.T) {
+	// RFC 7515, appendix A.1
+	const token = "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9" +
+		".eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ" +
+		".dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	key := mustBase64(t, "AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow")
+	sig := HMAC256(key, "")
+
+	var claims map[string]interface{}
+	if err := DecodeClaims([]byte(token), sig, &claims, WithClock(rfc7515Clock), WithType("JWT")); err != nil {
+		t.Fatalf("cannot decode: %s", err)
+	}
+	if !reflect.DeepEqual(claims, rfc7515Claims) {
+		t.Fatalf("unexpected claims: %v", claims)
+	}
+	assertSignature(t, sig, token)
+
+	if err := DecodeClaims([]byte(token), sig, nil); err != ErrExpired {
+		t.Fatalf("want ErrExpired, got %v", err)
+	}
+}
+
+func TestConformanceRFC7515RS256(t *testing.T) {
+	// RFC 7515, appendix A.2
+	const token = "eyJhbGciOiJSUzI1NiJ9" +
+		".eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ" +
+		".cC4hiUPoj9Eetdgtv3hF80EGrhuB__dzERat0XF9g2VtQgr9PJbu3XOiZj5RZmh7AAuHIm4Bh-0Qc_lF5YKt_O8W2Fp5jujGbds9uJdbF9CUAr7t1dnZcAcQjbKBYNX4BAynRFdiuB--f_nZLgrnbyTyWzO75vRK5h6xBArLIARNPvkSjtQBMHlb1L07Qe7K0GarZRmB_eSN9383LcOLn6_dO--xi12jzDwusC-eOkHWEsqtFZESc6BfI7noOPqvhJ1phCnvWh6IeYI2w9QOYEUipUTI8np6LbgGY9Fs98rqVt5AXLIhWkWywlVmtVrBp0igcN_IoypGlUPQGe77Rw"
+	key := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(mustBase64(t, "ofgWCuLjybRlzo0tZWJjNiuSfb4p4fAkd_wWJcyQoTbji9k0l8W26mPddxHmfHQp-Vaw-4qPCJrcS2mJPMEzP1Pt0Bm4d4QlL-yRT-SFd2lZS-pCgNMsD1W_YpRPEwOWvG6b32690r2jZ47soMZo9wGzjb_7OMg0LOL-bSf63kpaSHSXndS5z5rexMdbBYUsLA9e-KXBdQOS-UTo7WTBEMa2R2CapHg665xsmtdVMTBQY4uDZlxvb3qCo5ZwKh9kG4LT6_I5IhlJH7aGhyxXFvUK-DWNmoudF8NAco9_h9iaGNj8q2ethFkMLs91kzk2PAcDTW9gb54h4FRWyuXpoQ")),
+		E: 65537,
+	}
+
+	var claims map[string]interface{}
+	if err := DecodeClaims([]byte(token), RSA256Verifier(key), &claims, WithClock(rfc7515Clock)); err != nil {
+		t.Fatalf("cannot decode: %s", err)
+	}
+	if !reflect.DeepEqual(claims, rfc7515Claims) {
+		t.Fatalf("unexpected claims: %v", claims)
+	}
+
+	tampered := []byte(token)
+	tampered[len(tampered)-2] ^= 1
+	if err := DecodeClaims(tampered, RSA256Verifier(key), nil, WithClock(rfc7515Clock)); err != ErrInvalidSignature {
+		t.Fatalf("want ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestConformanceRFC7520HS256(t *testing.T) {
+	// RFC 7520, section 4.4. Payload is not JSON object, so signature is
+	// verified directly instead of decoding claims.
+	const token = "eyJhbGciOiJIUzI1NiIsImtpZCI6IjAxOGMwYWU1LTRkOWItNDcxYi1iZmQ2LWVlZjMxNGJjNzAzNyJ9" +
+		"." + rfc7520Payload +
+		".s0h6KThzkfBBBkLspW1h84VsJZFTsPPqMDA7g1Md7p0"
+	key := mustBase64(t, "hJtXIZ2uSN5kbQfbtTNWbpdmhkV8FJG-Onbc6mxCcYg")
+	sig := HMAC256(key, "018c0ae5-4d9b-471b-bfd6-eef314bc7037")
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	if err := DecodeHeader([]byte(token), &header); err != nil {
+		t.Fatalf("cannot decode header: %s", err)
+	}
+	if header.Algorithm != "HS256" || header.KeyID != "018c0ae5-4d9b-471b-bfd6-eef314bc7037" {
+		t.Fatalf("unexpected header: %+v", header)
+	}
+	assertSignature(t, sig, token)
+}
+
+// rfc7520Payload is the payload of RFC 7520 section 4 examples.
+const rfc7520Payload = "SXTigJlzIGEgZGFuZ2Vyb3VzIGJ1c2luZXNzLCBGcm9kbywgZ29pbmcgb3V0IHlvdXIgZG9vci4gWW91IHN0ZXAgb250byB0aGUgcm9hZCwgYW5kIGlmIHlvdSBkb24ndCBrZWVwIHlvdXIgZmVldCwgdGhlcmXigJlzIG5vIGtub3dpbmcgd2hlcmUgeW91IG1pZ2h0IGJlIHN3ZXB0IG9mZiB0by4"
+
+// rfc7520RSAKey returns RSA private key of RFC 7520, section 3.4.
+func rfc7520RSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	num := func(s string) *big.Int {
+		return new(big.Int).SetBytes(mustBase64(t, s))
+	}
+	key := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{
+			N: num("n4EPtAOCc9AlkeQHPzHStgAbgs7bTZLwUBZdR8_KuKPEHLd4rHVTeT-O-XV2jRojdNhxJWTDvNd7nqQ0VEiZQHz_AJmSCpMaJMRBSFKrKb2wqVwGU_NsYOYL-QtiWN2lbzcEe6XC0dApr5ydQLrHqkHHig3RBordaZ6Aj-oBHqFEHYpPe7Tpe-OfVfHd1E6cS6M1FZcD1NNLYD5lFHpPI9bTwJlsde3uhGqC0ZCuEHg8lhzwOHrtIQbS0FVbb9k3-tVTU4fg_3L_vniUFAKwuCLqKnS2BYwdq_mzSnbLY7h_qixoR7jig3__kRhuaxwUkRz5iaiQkqgc5gHdrNP5zw"),
+			E: 65537,
+		},
+		D: num("bWUC9B-EFRIo8kpGfh0ZuyGPvMNKvYWNtB_ikiH9k20eT-O1q_I78eiZkpXxXQ0UTEs2LsNRS-8uJbvQ-A1irkwMSMkK1J3XTGgdrhCku9gRldY7sNA_AKZGh-Q661_42rINLRCe8W-nZ34ui_qOfkLnK9QWDDqpaIsA-bMwWWSDFu2MUBYwkHTMEzLYGqOe04noqeq1hExBTHBOBdkMXiuFhUq1BU6l-DqEiWxqg82sXt2h-LMnT3046AOYJoRioz75tSUQfGCshWTBnP5uDjd18kKhyv07lhfSJdrPdM5Plyl21hsFf4L_mHCuoFau7gdsPfHPxxjVOcOpBrQzwQ"),
+		Primes: []*big.Int{
+			num("3Slxg_DwTXJcb6095RoXygQCAZ5RnAvZlno1yhHtnUex_fp7AZ_9nRaO7HX_-SFfGQeutao2TDjDAWU4Vupk8rw9JR0AzZ0N2fvuIAmr_WCsmGpeNqQnev1T7IyEsnh8UMt-n5CafhkikzhEsrmndH6LxOrvRJlsPp6Zv8bUq0k"),
+			num("uKE2dh-cTf6ERF4k4e_jy78GfPYUIaUyoSSJuBzp3Cubk3OCqs6grT8bR_cu0Dm1MZwWmtdqDyI95HrUeq3MP15vMMON8lHTeZu2lmKvwqW7anV5UzhM1iZ7z4yMkuUwFWoBvyY898EXvRD-hdqRxHlSqAZ192zB3pVFJ0s7pFc"),
+		},
+	}
+	if err := key.Validate(); err != nil {
+		t.Fatalf("invalid key: %s", err)
+	}
+	key.Precompute()
+	return key
+}
+
+func TestConformanceRFC7520RS256(t *testing.T) {
+	// RFC 7520, section 4.1, signed using key of section 3.4. Payload is
+	// not JSON object, so signature is verified directly instead of
+	// decoding claims.
+	const token = "eyJhbGciOiJSUzI1NiIsImtpZCI6ImJpbGJvLmJhZ2dpbnNAaG9iYml0b24uZXhhbXBsZSJ9" +
+		"." + rfc7520Payload +
+		".MRjdkly7_-oTPTS3AXP41iQIGKa80A0ZmTuV5MEaHoxnW2e5CZ5NlKtainoFmKZopdHM1O2U4mwzJdQx996ivp83xuglII7PNDi84wnB-BDkoBwA78185hX-Es4JIwmDLJK3lfWRa-XtL0RnltuYv746iYTh_qHRD68BNt1uSNCrUCTJDt5aAE6x8wW1Kt9eRo4QPocSadnHXFxnt8Is9UzpERV0ePPQdLuW3IS_de3xyIrDaLGdjluPxUAhb6L2aXic1U12podGU0KLUQSE_oI-ZnmKJ3F4uOZDnd6QZWJushZ41Axf_fcIe8u9ipH84ogoree7vjbU5y18kDquDg"
+	key := rfc7520RSAKey(t)
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	if err := DecodeHeader([]byte(token), &header); err != nil {
+		t.Fatalf("cannot decode header: %s", err)
+	}
+	if header.Algorithm != "RS256" || header.KeyID != "bilbo.baggins@hobbiton.example" {
+		t.Fatalf("unexpected header: %+v", header)
+	}
+	// PKCS #1 v1.5 signatures are deterministic, so the published one must
+	// be reproduced exactly
+	assertSignature(t, RSA256Signer(key, "bilbo.baggins@hobbiton.example"), token)
+
+	i := strings.LastIndexByte(token, '.')
+	signature := mustBase64(t, token[i+1:])
+	if err := RSA256Verifier(&key.PublicKey).Verify(signature, []byte(token[:i])); err != nil {
+		t.Fatalf("cannot verify with public key: %s", err)
+	}
+	signature[0] ^= 1
+	if err := RSA256Verifier(&key.PublicKey).Verify(signature, []byte(token[:i])); err != ErrInvalidSignature {
+		t.Fatalf("want ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestConformancePyJWTRS384(t *testing.T) {
+	// control token of PyJWT test suite (tests/test_api_jws.py), created
+	// by another library and signed using tests/keys/testkey_rsa key
+	const token = "eyJhbGciOiJSUzM4NCIsInR5cCI6IkpXVCJ9" +
+		".eyJoZWxsbyI6IndvcmxkIn0" +
+		".yNQ3nI9vEDs7lEh-Cp81McPuiQ4ZRv6FL4evTYYAh1X" +
+		"lRTTR3Cz8pPA9Stgso8Ra9xGB4X3rlra1c8Jz10nTUju" +
+		"O06OMm7oXdrnxp1KIiAJDerWHkQ7l3dlizIk1bmMA457" +
+		"W2fNzNfHViuED5ISM081dgf_a71qBwJ_yShMMrSOfxDx" +
+		"mX9c4DjRogRJG8SM5PvpLqI_Cm9iQPGMvmYK7gzcq2cJ" +
+		"urHRJDJHTqIdpLWXkY7zVikeen6FhuGyn060Dz9gYq9t" +
+		"uwmrtSWCBUjiN8sqJ00CDgycxKqHfUndZbEAOjcCAhBr" +
+		"qWW3mSVivUfubsYbwUdUG3fSRPjaUPcpe8A"
+	key := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(mustBase64(t, "1HgzBfJv2cOjQryCwe8NEelriOTNFWKZUivevUrRhlqcmZJdCvuCJRr-xCN-OmO8qwgJJR98feNujxVg-J9Ls3_UOA4HcF9nYH6aqVXELAE8Hk_ALvxi96ms1DDuAvQGaYZ-lANxlvxeQFOZSbjkz_9mh8aLeGKwqJLp3p-OhUBQpwvAUAPg82-OUtgTW3nSljjeFr14B8qAneGSc_wl0ni--1SRZUXFSovzcqQOkla3W27rrLfrD6LXgj_TsDs4vD1PnIm1zcVenKT7TfYI17bsG_O_Wecwz2Nl19pL7gDosNruF3ogJWNq1Lyn_ijPQnkPLpZHyhvuiycYcI3DiQ")),
+		E: 65537,
+	}
+
+	var claims map[string]interface{}
+	if err := DecodeClaims([]byte(token), RSA384Verifier(key), &claims); err != nil {
+		t.Fatalf("cannot decode: %s", err)
+	}
+	if want := map[string]interface{}{"hello": "world"}; !reflect.DeepEqual(claims, want) {
+		t.Fatalf("unexpected claims: %v", claims)
+	}
+	if err := DecodeClaims([]byte(token), RSA512Verifier(key), nil); err != ErrInvalidSigner {
+		t.Fatalf("want ErrInvalidSigner, got %v", err)
+	}
+}
+
+func TestRSAVariantsRoundTrip(t *testing.T) {
+	// no published vectors are used here, this only makes sure that every
+	// variant signs using its own algorithm and hash
+	key := rfc7520RSAKey(t)
+
+	cases := map[string]struct {
+		signer   Signer
+		verifier Verifier
+	}{
+		"RS256": {
+			signer:   RSA256Signer(key, "bilbo.baggins@hobbiton.example"),
+			verifier: RSA256Verifier(&key.PublicKey),
+		},
+		"RS384": {
+			signer:   RSA384Signer(key, "bilbo.baggins@hobbiton.example"),
+			verifier: RSA384Verifier(&key.PublicKey),
+		},
+		"RS512": {
+			signer:   RSA512Signer(key, "bilbo.baggins@hobbiton.example"),
+			verifier: RSA512Verifier(&key.PublicKey),
+		},
+	}
+
+	for tname, tc := range cases {
+		if tc.signer.Algorithm() != tname || tc.verifier.Algorithm() != tname {
+			t.Errorf("%s: unexpected algorithm %q", tname, tc.signer.Algorithm())
+			continue
+		}
+		token, err := Encode(tc.signer, rfc7515Claims)
+		if err != nil {
+			t.Fatalf("%s: cannot encode: %s", tname, err)
+		}
+		var claims map[string]interface{}
+		if err := DecodeClaims(token, tc.verifier, &claims, WithClock(rfc7515Clock)); err != nil {
+			t.Errorf("%s: cannot decode: %s", tname, err)
+			continue
+		}
+		if !reflect.DeepEqual(claims, rfc7515Claims) {
+			t.Errorf("%s: unexpected claims: %v", tname, claims)
+		}
+		// signature of other variant must not be accepted
+		for other, oc := range cases {
+			if other == tname {
+				continue
+			}
+			i := bytes.LastIndexByte(token, '.')
+			if err := oc.verifier.Verify(mustBase64(t, string(token[i+1:])), token[:i]); err != ErrInvalidSignature {
+				t.Errorf("%s: want %s verifier to reject signature, got %v", tname, other, err)
+			}
+		}
+	}
+}
+
+func TestConformanceRFC4231HMAC(t *testing.T) {
+	// RFC 4231, test case 2
+	key := []byte("Jefe")
+	data := []byte("what do ya want for nothing?")
+
+	cases := map[string]struct {
+		signer Signer
+		want   string
+	}{
+		"HS256": {
+			signer: HMAC256(key, ""),
+			want:   "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		},
+		"HS384": {
+			signer: HMAC384(key, ""),
+			want:   "af45d2e376484031617f78d2b58a6b1b9c7ef464f5a01b47e42ec3736322445e8e2240ca5e69e2c78b3239ecfab21649",
+		},
+		"HS512": {
+			signer: HMAC512(key, ""),
+			want:   "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737",
+		},
+	}
+
+	for tname, tc := range cases {
+		got, err := tc.signer.Sign(data)
+		if err != nil {
+			t.Fatalf("%s: cannot sign: %s", tname, err)
+		}
+		if hex.EncodeToString(got) != tc.want {
+			t.Errorf("%s: want %s, got %x", tname, tc.want, got)
+		}
+		if tc.signer.Algorithm() != tname {
+			t.Errorf("%s: unexpected algorithm %q", tname, tc.signer.Algorithm())
+		}
+	}
+}
+
+func TestConformanceRFC7638Thumbprint(t *testing.T) {
+	// RFC 7638, section 3.1
+	jwk := JWK{
+		KeyType: "RSA",
+		N:       "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:       "AQAB",
+		KeyID:   "2011-04-29",
+	}
+	got, err := jwk.Thumbprint()
+	if err != nil {
+		t.Fatalf("cannot compute thumbprint: %s", err)
+	}
+	if want := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; got != want {
+		t.Fatalf("want %s, got %s", want, got)
+	}
+}
+
+// assertSignature checks that signer computes the same signature as the one
+// of given token.
+func assertSignature(t *testing.T, sig Signer, token string) {
+	t.Helper()
+	i := bytes.LastIndexByte([]byte(token), '.')
+	got, err := sig.Sign([]byte(token[:i]))
+	if err != nil {
+		t.Fatalf("cannot sign: %s", err)
+	}
+	if want := mustBase64(t, token[i+1:]); !bytes.Equal(got, want) {
+		t.Fatalf("want signature %x, got %x", want, got)
+	}
+	if err := sig.Verify(got, []byte(token[:i])); err != nil {
+		t.Fatalf("cannot verify: %s", err)
+	}
+}
+
+func mustBase64(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatalf("cannot decode %q: %s", s, err)
+	}
+	return b
+}
@@ -0,0 +1,82 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Provider is OpenID Connect provider metadata as defined in OpenID Connect
+// Discovery 1.0, section 3. Only fields relevant for token validation are
+// decoded.
+type Provider struct {
+	Issuer                           string   `json:"issuer"`
+	AuthorizationEndpoint            string   `json:"authorization_endpoint"`
+	TokenEndpoint                    string   `json:"token_endpoint,omitempty"`
+	UserInfoEndpoint                 string   `json:"userinfo_endpoint,omitempty"`
+	JWKSURI                          string   `json:"jwks_uri"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+
+	keys *RemoteKeySet
+}
+
+// Discover fetches OpenID Connect provider metadata for given issuer from
+// its "/.well-known/openid-configuration" document.
+//
+// Issuer declared by the document must be exactly the same as the one that
+// metadata was requested for, as required by OpenID Connect Discovery 1.0,
+// section 4.3.
+//
+// client is optional (can be nil) HTTP client, http.DefaultClient is used
+// otherwise. The same client is used to fetch provider keys.
+func Discover(ctx context.Context, issuer string, client *http.Client) (*Provider, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	url := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	var p Provider
+	if err := getJSON(ctx, client, url, &p); err != nil {
+		return nil, fmt.Errorf("cannot fetch provider configuration: %s", err)
+	}
+	if p.Issuer != issuer {
+		return nil, fmt.Errorf("issuer mismatch: want %q, got %q", issuer, p.Issuer)
+	}
+	if p.JWKSURI == "" {
+		return nil, errors.New("provider does not declare jwks_uri")
+	}
+	if len(p.IDTokenSigningAlgValuesSupported) == 0 {
+		return nil, errors.New("provider does not declare id_token_signing_alg_values_supported")
+	}
+
+	// unsigned ID tokens must never be accepted
+	var algs []string
+	for _, alg := range p.IDTokenSigningAlgValuesSupported {
+		if alg != "none" {
+			algs = append(algs, alg)
+		}
+	}
+	if len(algs) == 0 {
+		return nil, errors.New("provider does not support signed ID tokens")
+	}
+	p.keys = NewRemoteKeySet(p.JWKSURI, client, algs...)
+	return &p, nil
+}
+
+// KeySet returns key set backed by provider "jwks_uri", accepting only
+// algorithms advertised by the provider.
+func (p *Provider) KeySet() *RemoteKeySet {
+	return p.keys
+}
+
+// IDTokenVerifier returns verifier of ID tokens issued by this provider for
+// given client.
+func (p *Provider) IDTokenVerifier(clientID string) *IDTokenVerifier {
+	return &IDTokenVerifier{
+		Verifier: p.keys,
+		Issuer:   p.Issuer,
+		ClientID: clientID,
+	}
+}
@@ -0,0 +1,258 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDiscover(t *testing.T) {
+	rot := NewRotatingSigner(time.Hour, nil)
+	if err := rot.Add(RSA256Signer(privRSA2048, "first"), time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("cannot add key: %s", err)
+	}
+
+	var (
+		issuer     string
+		jwksserved int
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                issuer,
+			"authorization_endpoint":                issuer + "/authorize",
+			"jwks_uri":                              issuer + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256", "none"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		jwksserved++
+		json.NewEncoder(w).Encode(rot.JWKS())
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	issuer = srv.URL
+
+	if _, err := Discover(context.Background(), srv.URL+"/other", srv.Client()); err == nil {
+		t.Fatal("want error for unknown provider")
+	}
+	issuer = "https://other.example.com"
+	if _, err := Discover(context.Background(), srv.URL, srv.Client()); err == nil {
+		t.Fatal("want issuer mismatch error")
+	}
+	issuer = srv.URL
+
+	provider, err := Discover(context.Background(), srv.URL, srv.Client())
+	if err != nil {
+		t.Fatalf("cannot discover: %s", err)
+	}
+	iv := provider.IDTokenVerifier("client")
+
+	encode := func(sig Signer) []byte {
+		token, err := Encode(sig, &IDTokenClaims{
+			Issuer:         issuer,
+			Subject:        "alice",
+			Audience:       Audience{"client"},
+			ExpirationTime: time.Now().Add(time.Hour).Unix(),
+			IssuedAt:       time.Now().Unix(),
+		})
+		if err != nil {
+			t.Fatalf("cannot encode: %s", err)
+		}
+		return token
+	}
+
+	if _, err := iv.Verify(encode(rot), IDTokenParams{}); err != nil {
+		t.Fatalf("cannot verify: %s", err)
+	}
+	if _, err := iv.Verify(encode(noneSigner{}), IDTokenParams{}); err != ErrInvalidSigner {
+		t.Fatalf("want ErrInvalidSigner for unsigned token, got %v", err)
+	}
+	if _, err := iv.Verify(encode(HMAC256([]byte("secret"), "first")), IDTokenParams{}); err != ErrInvalidSigner {
+		t.Fatalf("want ErrInvalidSigner for not advertised algorithm, got %v", err)
+	}
+
+	// key rotated, verifier must fetch new key set, but only once
+	if err := rot.Add(RSA256Signer(privRSA2048, "second"), time.Now()); err != nil {
+		t.Fatalf("cannot add key: %s", err)
+	}
+	provider.KeySet().MinRefreshInterval = 0
+	if _, err := iv.Verify(encode(rot), IDTokenParams{}); err != nil {
+		t.Fatalf("cannot verify after rotation: %s", err)
+	}
+	if jwksserved != 2 {
+		t.Fatalf("want key set fetched twice, got %d", jwksserved)
+	}
+}
+
+func TestRemoteKeySetContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// never respond, unless request is canceled
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	keys := NewRemoteKeySet(srv.URL, srv.Client())
+	token, err := Encode(RSA256Signer(privRSA2048, "key"), map[string]string{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = DecodeClaimsContext(ctx, token, keys, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded error, got %v", err)
+	}
+	if c := ErrorCategory(err); c != "canceled" {
+		t.Fatalf("want canceled category, got %q", c)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("key fetch not canceled, took %s", d)
+	}
+}
+
+func TestRemoteKeySetConcurrentFetch(t *testing.T) {
+	rot := NewRotatingSigner(time.Hour, nil)
+	if err := rot.Add(RSA256Signer(privRSA2048, "key"), time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("cannot add key: %s", err)
+	}
+	var requests int32
+	started := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// first request never completes, unless canceled
+		if atomic.AddInt32(&requests, 1) == 1 {
+			close(started)
+			<-r.Context().Done()
+			return
+		}
+		json.NewEncoder(w).Encode(rot.JWKS())
+	}))
+	defer srv.Close()
+	keys := NewRemoteKeySet(srv.URL, srv.Client())
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := keys.LookupContext(firstCtx, "RS256", "key")
+		firstErr <- err
+	}()
+	<-started
+
+	// caller waiting for fetch in progress must respect its own deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := keys.LookupContext(ctx, "RS256", "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded error, got %v", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("waiting for fetch not canceled, took %s", d)
+	}
+
+	// canceled fetch must not fail other callers
+	secondErr := make(chan error, 1)
+	go func() {
+		_, err := keys.LookupContext(context.Background(), "RS256", "key")
+		secondErr <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancelFirst()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("want canceled error, got %v", err)
+	}
+	if err := <-secondErr; err != nil {
+		t.Fatalf("cannot lookup key: %s", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Fatalf("want two requests, got %d", n)
+	}
+}
+
+func TestRemoteKeySetFailedFetch(t *testing.T) {
+	rot := NewRotatingSigner(time.Hour, nil)
+	if err := rot.Add(RSA256Signer(privRSA2048, "key"), time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("cannot add key: %s", err)
+	}
+	var requests int32
+	down := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(rot.JWKS())
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	keys := NewRemoteKeySet(srv.URL, srv.Client())
+	keys.Now = func() time.Time { return now }
+
+	// provider that is down is asked again only after refresh interval
+	for i := 0; i < 3; i++ {
+		if _, err := keys.Lookup("RS256", "key"); err == nil {
+			t.Fatal("want error while provider is down")
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Fatalf("want one request, got %d", n)
+	}
+
+	down = false
+	now = now.Add(keys.MinRefreshInterval)
+	if _, err := keys.Lookup("RS256", "key"); err != nil {
+		t.Fatalf("cannot lookup key: %s", err)
+	}
+
+	// failed refresh keeps keys fetched before
+	down = true
+	now = now.Add(keys.MinRefreshInterval)
+	if _, err := keys.Lookup("RS256", "unknown"); err == nil {
+		t.Fatal("want error for unknown key")
+	}
+	if _, err := keys.Lookup("RS256", "unknown"); err != ErrInvalidSigner {
+		t.Fatalf("want ErrInvalidSigner, got %v", err)
+	}
+	if _, err := keys.Lookup("RS256", "key"); err != nil {
+		t.Fatalf("cannot lookup key: %s", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 3 {
+		t.Fatalf("want three requests, got %d", n)
+	}
+}
+
+func TestRemoteKeySetAlgorithms(t *testing.T) {
+	rot := NewRotatingSigner(time.Hour, nil)
+	if err := rot.Add(RSA256Signer(privRSA2048, "key"), time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("cannot add key: %s", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(rot.JWKS())
+	}))
+	defer srv.Close()
+
+	data := []byte("data")
+	signature, err := rot.Sign(data)
+	if err != nil {
+		t.Fatalf("cannot sign: %s", err)
+	}
+	if err := NewRemoteKeySet(srv.URL, srv.Client()).Verify(signature, data); err != nil {
+		t.Fatalf("cannot verify: %s", err)
+	}
+
+	restricted := NewRemoteKeySet(srv.URL, srv.Client(), "RS512")
+	if err := restricted.Verify(signature, data); err != ErrInvalidSignature {
+		t.Fatalf("want ErrInvalidSignature, got %v", err)
+	}
+	if _, err := restricted.Lookup("RS256", "key"); err != ErrInvalidSigner {
+		t.Fatalf("want ErrInvalidSigner, got %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DPoPProofType is the token type ("typ") header value of DPoP proofs as
+// defined in RFC 9449, section 4.2.
+const DPoPProofType = "dpop+jwt"
+
+// DPoPProofClaims holds claims of DPoP proof as defined in RFC 9449, section
+// 4.2.
+type DPoPProofClaims struct {
+	TokenID         string `json:"jti"`
+	HTTPMethod      string `json:"htm"`
+	HTTPURI         string `json:"htu"`
+	IssuedAt        int64  `json:"iat"`
+	AccessTokenHash string `json:"ath,omitempty"`
+	Nonce           string `json:"nonce,omitempty"`
+}
+
+// DPoPRequest describes HTTP request that DPoP proof is created for or
+// validated against.
+type DPoPRequest struct {
+	// Method is HTTP request method, for example "POST".
+	Method string
+
+	// URI is HTTP request URI. Query and fragment parts are ignored.
+	URI string
+
+	// AccessToken, if not empty, binds proof to given access token using
+	// "ath" claim. It must be provided when accessing protected resource.
+	AccessToken string
+
+	// Nonce, if not empty, is the last nonce provided by the server.
+	Nonce string
+}
+
+// NewDPoPProof returns DPoP proof for given request, signed using given
+// signer. Signer must provide public key, that is embedded in the proof
+// header.
+func NewDPoPProof(sig Signer, req DPoPRequest) ([]byte, error) {
+	jwk, err := PublicJWK(sig)
+	if err != nil {
+		return nil, err
+	}
+	jwk.KeyID = ""
+
+	tokenID, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	claims := DPoPProofClaims{
+		TokenID:    tokenID,
+		HTTPMethod: req.Method,
+		HTTPURI:    stripQuery(req.URI),
+		IssuedAt:   time.Now().Unix(),
+		Nonce:      req.Nonce,
+	}
+	if req.AccessToken != "" {
+		claims.AccessTokenHash = accessTokenHash(req.AccessToken)
+	}
+	return Encode(sig, &claims, EncodeWithType(DPoPProofType), EncodeWithHeader("jwk", &jwk))
+}
+
+// DPoPVerifier validates DPoP proofs as described by RFC 9449, section 4.3.
+type DPoPVerifier struct {
+	// Store keeps track of already used proof IDs. It is required,
+	// because proofs must not be replayed.
+	Store ReplayStore
+
+	// MaxAge is the maximum accepted age of the proof. Proofs issued in
+	// the future are accepted within the same window, to allow for clock
+	// skew. Defaults to one minute.
+	MaxAge time.Duration
+
+	// Algorithms, if not empty, limits accepted signature algorithms.
+	Algorithms []string
+
+	// Now is optional (can be nil) function returning current time. It
+	// is useful mostly for testing.
+	Now func() time.Time
+}
+
+// Verify validates DPoP proof for given request and returns JWK thumbprint
+// of the key that proof was signed with. Thumbprint must be compared with
+// the access token binding, see CheckDPoPBinding.
+func (dv *DPoPVerifier) Verify(proof []byte, req DPoPRequest) (string, error) {
+	now := time.Now
+	if dv.Now != nil {
+		now = dv.Now
+	}
+	maxAge := dv.MaxAge
+	if maxAge == 0 {
+		maxAge = time.Minute
+	}
+	if dv.Store == nil {
+		return "", errors.New("replay store is required")
+	}
+
+	tok, err := ParseUnverified(proof)
+	if err != nil {
+		return "", err
+	}
+	alg := tok.Algorithm()
+	if alg == "none" || strings.HasPrefix(alg, "HS") || !algorithmAllowed(dv.Algorithms, alg) {
+		return "", ErrInvalidSigner
+	}
+	jwk, err := embeddedJWK(tok.Header)
+	if err != nil {
+		return "", err
+	}
+	v, err := jwk.Verifier(alg)
+	if err != nil {
+		return "", err
+	}
+
+	var claims DPoPProofClaims
+	opts := []DecodeOption{
+		WithClock(now),
+		WithType(DPoPProofType),
+		WithRequiredClaims("jti", "htm", "htu", "iat"),
+	}
+	if err := DecodeClaims(proof, v, &claims, opts...); err != nil {
+		return "", err
+	}
+
+	if claims.HTTPMethod != req.Method {
+		return "", &ClaimError{Claim: "htm", Err: errors.New("method mismatch")}
+	}
+	if !sameHTU(claims.HTTPURI, req.URI) {
+		return "", &ClaimError{Claim: "htu", Err: errors.New("URI mismatch")}
+	}
+	issuedAt := time.Unix(claims.IssuedAt, 0)
+	if d := now().Sub(issuedAt); d > maxAge || d < -maxAge {
+		return "", &ClaimError{Claim: "iat", Err: errors.New("proof issued outside of accepted window")}
+	}
+	if req.Nonce != "" && subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(req.Nonce)) != 1 {
+		return "", &ClaimError{Claim: "nonce", Err: errors.New("nonce mismatch")}
+	}
+	if req.AccessToken != "" {
+		if claims.AccessTokenHash == "" {
+			return "", &ClaimError{Claim: "ath", Err: ErrMissingClaim}
+		}
+		if subtle.ConstantTimeCompare([]byte(claims.AccessTokenHash), []byte(accessTokenHash(req.AccessToken))) != 1 {
+			return "", &ClaimError{Claim: "ath", Err: errors.New("access token hash mismatch")}
+		}
+	}
+
+	// proof is accepted within time window, so its ID must be remembered
+	// only as long as the window lasts
+	if err := dv.Store.Use(claims.TokenID, issuedAt.Add(maxAge)); err != nil {
+		return "", err
+	}
+
+	return jwk.Thumbprint()
+}
+
+// CheckDPoPBinding returns ErrDPoPBinding unless access token confirmation
+// claim binds it to the key with given JWK thumbprint, as described by RFC
+// 9449, section 6.1.
+func CheckDPoPBinding(cnf *Confirmation, thumbprint string) error {
+	if cnf == nil || cnf.JWKThumbprint == "" {
+		return ErrDPoPBinding
+	}
+	if subtle.ConstantTimeCompare([]byte(cnf.JWKThumbprint), []byte(thumbprint)) != 1 {
+		return ErrDPoPBinding
+	}
+	return nil
+}
+
+// ErrDPoPBinding is returned when access token is not bound to the DPoP
+// proof key.
+var ErrDPoPBinding = errors.New("access token not bound to DPoP proof key")
+
+// embeddedJWK returns public key embedded in the "jwk" header parameter.
+func embeddedJWK(header map[string]interface{}) (JWK, error) {
+	raw, ok := header["jwk"].(map[string]interface{})
+	if !ok {
+		return JWK{}, errors.New("missing jwk header")
+	}
+	// private key must never be sent
+	if _, ok := raw["d"]; ok {
+		return JWK{}, errors.New("jwk header contains private key")
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return JWK{}, err
+	}
+	var jwk JWK
+	if err := json.Unmarshal(b, &jwk); err != nil {
+		return JWK{}, fmt.Errorf("invalid jwk header: %s", err)
+	}
+	return jwk, nil
+}
+
+func algorithmAllowed(allowed []string, alg string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
+
+// accessTokenHash returns "ath" claim value for given access token.
+func accessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	b, _ := encode(sum[:])
+	return string(b)
+}
+
+// sameHTU returns true if both URIs are the same, ignoring query and
+// fragment parts and using syntax based normalization of scheme, host and
+// default port.
+func sameHTU(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return normalizedHTU(ua) == normalizedHTU(ub)
+}
+
+func normalizedHTU(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(scheme == "https" && port == "443") && !(scheme == "http" && port == "80") {
+		host += ":" + port
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return scheme + "://" + host + path
+}
+
+// stripQuery returns given URI without query and fragment parts.
+func stripQuery(uri string) string {
+	if i := strings.IndexAny(uri, "?#"); i >= 0 {
+		return uri[:i]
+	}
+	return uri
+}
+
+// randomID returns random, base64 encoded value suitable for token ID.
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("cannot generate token ID: %s", err)
+	}
+	id, _ := encode(b)
+	return string(id), nil
+}
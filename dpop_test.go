@@ -0,0 +1,107 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDPoP(t *testing.T) {
+	now := time.Now()
+	signer := RSA256Signer(privRSA2048, "device-key")
+	jwk, _ := PublicJWK(signer)
+	thumbprint, err := jwk.Thumbprint()
+	if err != nil {
+		t.Fatalf("cannot compute thumbprint: %s", err)
+	}
+
+	req := DPoPRequest{
+		Method:      "GET",
+		URI:         "https://resource.example.org/protectedresource?x=1",
+		AccessToken: "Kz~8mXK1EalYznwH-LC-1fBAo.4Ljp~zsPE_NeO.gxU",
+		Nonce:       "eyJ7S_zG.eyJH0-Z.HX4w-7v",
+	}
+	mustProof := func(req DPoPRequest) []byte {
+		proof, err := NewDPoPProof(signer, req)
+		if err != nil {
+			t.Fatalf("cannot create proof: %s", err)
+		}
+		return proof
+	}
+
+	dv := &DPoPVerifier{
+		Store: NewMemoryReplayStore(nil),
+		Now:   func() time.Time { return now },
+	}
+
+	proof := mustProof(req)
+	got, err := dv.Verify(proof, DPoPRequest{
+		Method:      "GET",
+		URI:         "HTTPS://Resource.example.org:443/protectedresource",
+		AccessToken: req.AccessToken,
+		Nonce:       req.Nonce,
+	})
+	if err != nil {
+		t.Fatalf("cannot verify proof: %s", err)
+	}
+	if got != thumbprint {
+		t.Fatalf("want %q thumbprint, got %q", thumbprint, got)
+	}
+	if err := CheckDPoPBinding(&Confirmation{JWKThumbprint: got}, thumbprint); err != nil {
+		t.Fatalf("cannot check binding: %s", err)
+	}
+	if err := CheckDPoPBinding(&Confirmation{JWKThumbprint: "other"}, thumbprint); err != ErrDPoPBinding {
+		t.Fatalf("want ErrDPoPBinding, got %v", err)
+	}
+	if _, err := dv.Verify(proof, req); err != ErrReplayed {
+		t.Fatalf("want ErrReplayed, got %v", err)
+	}
+
+	cases := map[string]struct {
+		req       DPoPRequest
+		now       time.Time
+		wantClaim string
+	}{
+		"method-mismatch": {
+			req:       DPoPRequest{Method: "POST", URI: req.URI},
+			wantClaim: "htm",
+		},
+		"uri-mismatch": {
+			req:       DPoPRequest{Method: "GET", URI: "https://resource.example.org/other"},
+			wantClaim: "htu",
+		},
+		"too-old": {
+			req:       DPoPRequest{Method: "GET", URI: req.URI},
+			now:       now.Add(2 * time.Minute),
+			wantClaim: "iat",
+		},
+		"nonce-mismatch": {
+			req:       DPoPRequest{Method: "GET", URI: req.URI, Nonce: "other"},
+			wantClaim: "nonce",
+		},
+		"access-token-mismatch": {
+			req:       DPoPRequest{Method: "GET", URI: req.URI, AccessToken: "other"},
+			wantClaim: "ath",
+		},
+	}
+	for tname, tc := range cases {
+		dv.Now = func() time.Time { return now }
+		if !tc.now.IsZero() {
+			dv.Now = func() time.Time { return tc.now }
+		}
+		_, err := dv.Verify(mustProof(req), tc.req)
+		var cerr *ClaimError
+		if !errors.As(err, &cerr) || cerr.Claim != tc.wantClaim {
+			t.Errorf("%s: want %q claim error, got %v", tname, tc.wantClaim, err)
+		}
+	}
+
+	// proofs signed with symmetric key are never valid
+	hmacProof, err := Encode(HMAC256([]byte("secret"), ""), DPoPProofClaims{}, EncodeWithType(DPoPProofType), EncodeWithHeader("jwk", jwk))
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+	if _, err := dv.Verify(hmacProof, req); err != ErrInvalidSigner {
+		t.Fatalf("want ErrInvalidSigner, got %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// fuzzVerifiers returns signers and verifiers of every type, together with
+// tokens they signed, to be used as fuzzing corpus.
+func fuzzVerifiers(tb testing.TB) (map[string]Verifier, [][]byte) {
+	hmacSig := HMAC256([]byte("fuzz secret"), "hmac-key")
+	rsaSig := RSA256Signer(privRSA, "rsa-key")
+	jwk, err := PublicJWK(rsaSig)
+	if err != nil {
+		tb.Fatalf("cannot create JWK: %s", err)
+	}
+	rotating := NewRotatingSigner(time.Hour, nil)
+	if err := rotating.Add(HMAC384([]byte("rotating secret"), "rotating-key"), time.Now().Add(-time.Hour)); err != nil {
+		tb.Fatalf("cannot add key: %s", err)
+	}
+
+	verifiers := map[string]Verifier{
+		"hmac":       hmacSig,
+		"rsa-signer": rsaSig,
+		"rsa":        RSA256Verifier(&privRSA.PublicKey),
+		"jwks":       &JWKSet{Keys: []JWK{jwk}},
+		"rotating":   rotating,
+		"none":       noneSigner{},
+	}
+	var tokens [][]byte
+	for _, sig := range []Signer{hmacSig, rsaSig, rotating, noneSigner{}} {
+		token, err := Encode(sig, map[string]interface{}{
+			"sub": "fuzz",
+			"exp": time.Now().Add(time.Hour).Unix(),
+			"nbf": 1,
+			"jti": "token-id",
+		}, EncodeWithType("JWT"))
+		if err != nil {
+			tb.Fatalf("cannot encode: %s", err)
+		}
+		tokens = append(tokens, token)
+	}
+	return verifiers, tokens
+}
+
+func FuzzDecodeHeader(f *testing.F) {
+	_, tokens := fuzzVerifiers(f)
+	for _, token := range tokens {
+		f.Add(token)
+	}
+	f.Add([]byte("e30.e30.")) // {}.{}.
+	f.Add([]byte("eyJhbGciOiJIUzI1NiJ9=.e30.e30"))
+
+	f.Fuzz(func(t *testing.T, token []byte) {
+		var header map[string]interface{}
+		_ = DecodeHeader(token, &header)
+	})
+}
+
+func FuzzDecodeClaims(f *testing.F) {
+	verifiers, tokens := fuzzVerifiers(f)
+	for _, token := range tokens {
+		f.Add(token)
+	}
+	f.Add([]byte("e30.e30."))
+	f.Add([]byte(`eyJhbGciOiJIUzI1NiIsImFsZyI6Im5vbmUifQ.eyJleHAiOjF9.`))
+
+	f.Fuzz(func(t *testing.T, token []byte) {
+		for name, v := range verifiers {
+			var claims map[string]interface{}
+			err := DecodeClaims(token, v, &claims, WithRequiredClaims("sub"), WithType("JWT"))
+			if err == nil && name != "none" && bytes.HasSuffix(token, []byte{'.'}) {
+				t.Fatalf("%s: token without signature verified: %q", name, token)
+			}
+			_ = DecodeClaims(token, v, nil)
+		}
+	})
+}
+
+func FuzzParseUnverified(f *testing.F) {
+	_, tokens := fuzzVerifiers(f)
+	for _, token := range tokens {
+		f.Add(token)
+	}
+	f.Add([]byte("e30.e30."))
+
+	f.Fuzz(func(t *testing.T, token []byte) {
+		tok, err := ParseUnverified(token)
+		if err != nil {
+			return
+		}
+		if !json.Valid(tok.Claims) {
+			t.Fatalf("invalid claims returned: %q", tok.Claims)
+		}
+		if !bytes.HasPrefix(token, tok.SigningInput) {
+			t.Fatalf("signing input %q is not prefix of %q", tok.SigningInput, token)
+		}
+	})
+}
+
+func FuzzScanObject(f *testing.F) {
+	f.Add([]byte(`{}`))
+	f.Add([]byte(`{"alg":"HS256","kid":"abc","n":[1,{"x":null}],"t":true}`))
+	f.Add([]byte(` { "exp" : 1.5e3 } `))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		err := scanObject(data, func(name, value []byte) error {
+			if !json.Valid(value) {
+				t.Fatalf("invalid member value %q", value)
+			}
+			return nil
+		})
+		if err == nil && !json.Valid(data) {
+			t.Fatalf("invalid JSON accepted: %q", data)
+		}
+	})
+}
+
+// FuzzTampered checks that token modified after signing never verifies,
+// unless the modification does not change any decoded part.
+func FuzzTampered(f *testing.F) {
+	f.Add("alice", uint(0), byte(1))
+	f.Add("bob", uint(40), byte(0x20))
+	f.Add("", uint(1000), byte(0xff))
+
+	verifiers, _ := fuzzVerifiers(f)
+	f.Fuzz(func(t *testing.T, subject string, pos uint, mask byte) {
+		for name, v := range verifiers {
+			sig, ok := v.(Signer)
+			if !ok || name == "none" {
+				continue
+			}
+			token, err := Encode(sig, map[string]interface{}{"sub": subject})
+			if err != nil {
+				t.Fatalf("%s: cannot encode: %s", name, err)
+			}
+			tampered := append([]byte{}, token...)
+			tampered[pos%uint(len(tampered))] ^= mask
+			// in strict mode every modification must be detected
+			if DecodeClaims(tampered, v, nil, WithStrictEncoding(true)) == nil && mask != 0 {
+				t.Fatalf("%s: tampered token verified in strict mode: %q", name, tampered)
+			}
+			if DecodeClaims(tampered, v, nil) != nil {
+				continue
+			}
+			if !sameSegments(t, token, tampered) {
+				t.Fatalf("%s: tampered token verified: %q", name, tampered)
+			}
+		}
+	})
+}
+
+// sameSegments returns true if all parts of both tokens decode to the same
+// values.
+func sameSegments(t *testing.T, a, b []byte) bool {
+	pa, err := splitToken(a)
+	if err != nil {
+		t.Fatalf("cannot split: %s", err)
+	}
+	pb, err := splitToken(b)
+	if err != nil {
+		return false
+	}
+	for _, seg := range [][2][]byte{
+		{pa.header, pb.header},
+		{pa.claims, pb.claims},
+		{pa.signature, pb.signature},
+	} {
+		da, err := decodeSegment(seg[0], false)
+		if err != nil {
+			t.Fatalf("cannot decode: %s", err)
+		}
+		db, err := decodeSegment(seg[1], false)
+		if err != nil || !bytes.Equal(da, db) {
+			return false
+		}
+	}
+	return true
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add("alice", int64(1), true)
+	f.Add("żółw   \"quoted\" </script>", int64(-42), false)
+
+	sig := HMAC256([]byte("round trip"), "")
+	f.Fuzz(func(t *testing.T, subject string, score int64, admin bool) {
+		if !utf8.ValidString(subject) {
+			// invalid UTF-8 is replaced during JSON encoding
+			return
+		}
+		type payload struct {
+			Subject string `json:"sub"`
+			Score   int64  `json:"score"`
+			Admin   bool   `json:"admin"`
+		}
+		want := payload{Subject: subject, Score: score, Admin: admin}
+		token, err := Encode(sig, &want)
+		if err != nil {
+			t.Fatalf("cannot encode: %s", err)
+		}
+		var got payload
+		if err := DecodeClaims(token, sig, &got); err != nil {
+			t.Fatalf("cannot decode %q: %s", token, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %+v, got %+v", want, got)
+		}
+	})
+}
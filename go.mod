@@ -0,0 +1,3 @@
+module github.com/opinary/jwt
+
+go 1.21
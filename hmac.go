@@ -3,7 +3,8 @@ package jwt
 import (
 	"crypto"
 	"crypto/hmac"
-	"fmt"
+	"hash"
+	"sync"
 
 	_ "crypto/sha256"
 	_ "crypto/sha512"
@@ -14,6 +15,15 @@ type hmacSigner struct {
 	keyID string
 	key   []byte
 	hash  crypto.Hash
+
+	// pool holds *hmacState, so that keyed hash state is not computed
+	// for every signature
+	pool sync.Pool
+}
+
+type hmacState struct {
+	mac hash.Hash
+	sum []byte
 }
 
 var _ Signer = (*hmacSigner)(nil)
@@ -23,7 +33,7 @@ func (s *hmacSigner) Algorithm() string {
 	return s.alg
 }
 
-func (s hmacSigner) KeyID() string {
+func (s *hmacSigner) KeyID() string {
 	return s.keyID
 }
 
@@ -32,11 +42,11 @@ func (s *hmacSigner) Sign(data []byte) ([]byte, error) {
 		return nil, ErrAlgorithmNotAvailable
 	}
 
-	hasher := hmac.New(s.hash.New, s.key)
-	if _, err := hasher.Write(data); err != nil {
-		return nil, fmt.Errorf("cannot encode data: %s", err)
-	}
-	return hasher.Sum(nil), nil
+	state := s.state()
+	defer s.pool.Put(state)
+	// hash.Hash Write never returns an error
+	state.mac.Write(data)
+	return state.mac.Sum(nil), nil
 }
 
 func (s *hmacSigner) Verify(signature, data []byte) error {
@@ -44,18 +54,37 @@ func (s *hmacSigner) Verify(signature, data []byte) error {
 		return ErrAlgorithmNotAvailable
 	}
 
-	hasher := hmac.New(s.hash.New, s.key)
-	if _, err := hasher.Write(data); err != nil {
-		return fmt.Errorf("cannot encode data: %s", err)
-	}
+	state := s.state()
+	defer s.pool.Put(state)
+	state.mac.Write(data)
+	state.sum = state.mac.Sum(state.sum[:0])
 
-	if !hmac.Equal(signature, hasher.Sum(nil)) {
+	if !hmac.Equal(signature, state.sum) {
 		return ErrInvalidSignature
 	}
 	return nil
 }
 
+// state returns reset HMAC state from the pool.
+func (s *hmacSigner) state() *hmacState {
+	if state, ok := s.pool.Get().(*hmacState); ok {
+		state.mac.Reset()
+		return state
+	}
+	return &hmacState{mac: hmac.New(s.hash.New, s.key)}
+}
+
+// NewHMAC256 is like HMAC256, but returns ErrWeakKey if key is shorter than
+// 32 bytes, unless AllowWeakKeys option is used.
+func NewHMAC256(key []byte, keyID string, opts ...KeyOption) (Signer, error) {
+	if err := checkHMACKey(key, crypto.SHA256, opts); err != nil {
+		return nil, err
+	}
+	return HMAC256(key, keyID), nil
+}
+
 // HMAC256 returns signer using symetric key and SHA256 hashing function.
+// Key strength is not validated, see NewHMAC256.
 func HMAC256(key []byte, keyID string) Signer {
 	return &hmacSigner{
 		alg:   "HS256",
@@ -65,7 +94,17 @@ func HMAC256(key []byte, keyID string) Signer {
 	}
 }
 
+// NewHMAC384 is like HMAC384, but returns ErrWeakKey if key is shorter than
+// 48 bytes, unless AllowWeakKeys option is used.
+func NewHMAC384(key []byte, keyID string, opts ...KeyOption) (Signer, error) {
+	if err := checkHMACKey(key, crypto.SHA384, opts); err != nil {
+		return nil, err
+	}
+	return HMAC384(key, keyID), nil
+}
+
 // HMAC384 returns signer using symetric key and SHA384 hashing function.
+// Key strength is not validated, see NewHMAC384.
 func HMAC384(key []byte, keyID string) Signer {
 	return &hmacSigner{
 		alg:   "HS384",
@@ -75,7 +114,17 @@ func HMAC384(key []byte, keyID string) Signer {
 	}
 }
 
+// NewHMAC512 is like HMAC512, but returns ErrWeakKey if key is shorter than
+// 64 bytes, unless AllowWeakKeys option is used.
+func NewHMAC512(key []byte, keyID string, opts ...KeyOption) (Signer, error) {
+	if err := checkHMACKey(key, crypto.SHA512, opts); err != nil {
+		return nil, err
+	}
+	return HMAC512(key, keyID), nil
+}
+
 // HMAC512 returns signer using symetric key and SHA512 hashing function.
+// Key strength is not validated, see NewHMAC512.
 func HMAC512(key []byte, keyID string) Signer {
 	return &hmacSigner{
 		alg:   "HS512",
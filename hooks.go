@@ -0,0 +1,114 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Hooks is the interface implemented by observers of token decoding, for
+// example to collect metrics or log verification failures. See WithHooks.
+type Hooks interface {
+	// TokenDecoded is called once decoding of the token finished,
+	// successfully or not. It must not block.
+	TokenDecoded(ctx context.Context, event DecodeEvent)
+}
+
+// DecodeEvent describes outcome of the token decoding. It never contains
+// token claims or signature.
+//
+// Algorithm, KeyID and Issuer are read from the token before it is
+// verified, so for failed decoding they are controlled by the token author.
+// They are empty if decoding failed before they could be read, and are
+// truncated to 256 bytes.
+type DecodeEvent struct {
+	Algorithm string
+	KeyID     string
+	Issuer    string
+
+	// Duration is the time spent decoding, including key lookup and
+	// signature verification.
+	Duration time.Duration
+
+	// Err is the error returned by decoding or nil on success.
+	Err error
+
+	// Category is the error category as returned by ErrorCategory.
+	Category string
+}
+
+// WithHooks returns option that makes decoding report its outcome to given
+// hooks. If used more than once, all hooks are called in the order they were
+// given.
+func WithHooks(hooks Hooks) DecodeOption {
+	return func(o *decodeOptions) {
+		switch h := o.hooks.(type) {
+		case nil:
+			o.hooks = hooks
+		case multiHooks:
+			o.hooks = append(h[:len(h):len(h)], hooks)
+		default:
+			o.hooks = multiHooks{h, hooks}
+		}
+	}
+}
+
+type multiHooks []Hooks
+
+func (m multiHooks) TokenDecoded(ctx context.Context, event DecodeEvent) {
+	for _, h := range m {
+		h.TokenDecoded(ctx, event)
+	}
+}
+
+// ErrorCategory returns short, stable name of the error kind returned by
+// decoding, suitable for metric labels:
+//
+//	ok, malformed, limits, type, signer, signature, expired, not_ready,
+//	claims, replay, canceled, other
+//
+// "ok" is returned for nil error.
+func ErrorCategory(err error) string {
+	var (
+		segErr   *SegmentError
+		claimErr *ClaimError
+	)
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	case errors.Is(err, ErrMalformedToken) || errors.As(err, &segErr):
+		return "malformed"
+	case errors.Is(err, ErrTokenTooLarge) || errors.Is(err, ErrHeaderTooLarge) || errors.Is(err, ErrClaimsTooLarge) ||
+		errors.Is(err, ErrNestingTooDeep) || errors.Is(err, ErrDuplicateMember):
+		return "limits"
+	case errors.Is(err, ErrInvalidType):
+		return "type"
+	case errors.Is(err, ErrInvalidSigner) || errors.Is(err, ErrUnsupportedKey) || errors.Is(err, ErrWeakKey):
+		return "signer"
+	case errors.Is(err, ErrInvalidSignature):
+		return "signature"
+	case errors.Is(err, ErrExpired):
+		return "expired"
+	case errors.Is(err, ErrNotReady):
+		return "not_ready"
+	case errors.As(err, &claimErr) || errors.Is(err, ErrMissingClaim):
+		return "claims"
+	case errors.Is(err, ErrReplayed) || errors.Is(err, ErrRevoked) || errors.Is(err, ErrMissingTokenID):
+		return "replay"
+	default:
+		return "other"
+	}
+}
+
+// maxEventString is the maximum length of strings copied from token to
+// DecodeEvent.
+const maxEventString = 256
+
+func eventString(b []byte) string {
+	if len(b) > maxEventString {
+		b = b[:maxEventString]
+	}
+	return string(b)
+}
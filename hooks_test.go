@@ -0,0 +1,139 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recordingHooks struct {
+	events []DecodeEvent
+}
+
+func (h *recordingHooks) TokenDecoded(ctx context.Context, event DecodeEvent) {
+	h.events = append(h.events, event)
+}
+
+func TestWithHooks(t *testing.T) {
+	signer := HMAC256([]byte("top secret 7720132"), "hmac-key")
+	encode := func(claims map[string]interface{}) []byte {
+		token, err := Encode(signer, claims)
+		if err != nil {
+			t.Fatalf("cannot encode: %s", err)
+		}
+		return token
+	}
+
+	cases := map[string]struct {
+		token        []byte
+		wantCategory string
+		wantIssuer   string
+		wantAlg      string
+	}{
+		"ok": {
+			token:        encode(map[string]interface{}{"iss": "https://issuer.example.com"}),
+			wantCategory: "ok",
+			wantIssuer:   "https://issuer.example.com",
+			wantAlg:      "HS256",
+		},
+		"expired": {
+			token:        encode(map[string]interface{}{"iss": "issuer", "exp": 1}),
+			wantCategory: "expired",
+			wantIssuer:   "issuer",
+			wantAlg:      "HS256",
+		},
+		"malformed": {
+			token:        []byte("not a token"),
+			wantCategory: "malformed",
+		},
+		"long-issuer": {
+			token:        encode(map[string]interface{}{"iss": strings.Repeat("x", 1000)}),
+			wantCategory: "ok",
+			wantIssuer:   strings.Repeat("x", maxEventString),
+			wantAlg:      "HS256",
+		},
+	}
+
+	for tname, tc := range cases {
+		hooks := &recordingHooks{}
+		err := DecodeClaims(tc.token, signer, nil, WithHooks(hooks))
+		if len(hooks.events) != 1 {
+			t.Errorf("%s: want one event, got %d", tname, len(hooks.events))
+			continue
+		}
+		e := hooks.events[0]
+		if e.Err != err || e.Category != tc.wantCategory || e.Issuer != tc.wantIssuer || e.Algorithm != tc.wantAlg {
+			t.Errorf("%s: unexpected event: %+v", tname, e)
+		}
+		if tc.wantAlg != "" && e.KeyID != "hmac-key" {
+			t.Errorf("%s: want key ID, got %q", tname, e.KeyID)
+		}
+		if e.Duration <= 0 {
+			t.Errorf("%s: duration not measured", tname)
+		}
+	}
+}
+
+func TestWithHooksMultiple(t *testing.T) {
+	signer := HMAC256([]byte("top secret 7720132"), "")
+	token, err := Encode(signer, map[string]interface{}{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+
+	var hooks [3]recordingHooks
+	err = DecodeClaims(token, signer, nil, WithHooks(&hooks[0]), WithHooks(&hooks[1]), WithHooks(&hooks[2]))
+	if err != nil {
+		t.Fatalf("cannot decode: %s", err)
+	}
+	for i, h := range hooks {
+		if len(h.events) != 1 {
+			t.Errorf("hooks %d: want one event, got %d", i, len(h.events))
+		}
+	}
+}
+
+func TestErrorCategory(t *testing.T) {
+	cases := map[error]string{
+		nil:               "ok",
+		ErrMalformedToken: "malformed",
+		&SegmentError{Segment: "claims", Err: ErrNonCanonicalEncoding}: "malformed",
+		ErrTokenTooLarge:    "limits",
+		ErrDuplicateMember:  "limits",
+		ErrInvalidType:      "type",
+		ErrInvalidSigner:    "signer",
+		ErrInvalidSignature: "signature",
+		fmt.Errorf("cannot sign: %w", ErrInvalidSignature): "signature",
+		ErrExpired:  "expired",
+		ErrNotReady: "not_ready",
+		&ClaimError{Claim: "aud", Err: ErrMissingClaim}: "claims",
+		ErrReplayed:              "replay",
+		context.DeadlineExceeded: "canceled",
+		errors.New("boom"):       "other",
+	}
+	for err, want := range cases {
+		if got := ErrorCategory(err); got != want {
+			t.Errorf("%v: want %q, got %q", err, want, got)
+		}
+	}
+}
+
+func BenchmarkDecodeClaimsHooks(b *testing.B) {
+	signer := HMAC256([]byte("top secret 7720132"), "")
+	token, err := Encode(signer, map[string]interface{}{"iss": "issuer", "exp": time.Now().Add(time.Hour).Unix()})
+	if err != nil {
+		b.Fatalf("cannot encode: %s", err)
+	}
+	hooks := &recordingHooks{}
+	opt := WithHooks(hooks)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		hooks.events = hooks.events[:0]
+		if err := DecodeClaims(token, signer, nil, opt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
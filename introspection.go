@@ -0,0 +1,138 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// IntrospectionType is the token type ("typ") header value of JWT
+// introspection responses as defined in RFC 9701, section 5.
+const IntrospectionType = "token-introspection+jwt"
+
+// Introspection holds token introspection response members as defined in
+// RFC 7662, section 2.2. It can be embedded in a structure defining
+// additional members.
+type Introspection struct {
+	Active         bool     `json:"active"`
+	Scope          string   `json:"scope,omitempty"`
+	ClientID       string   `json:"client_id,omitempty"`
+	Username       string   `json:"username,omitempty"`
+	TokenType      string   `json:"token_type,omitempty"`
+	ExpirationTime int64    `json:"exp,omitempty"`
+	IssuedAt       int64    `json:"iat,omitempty"`
+	NotBefore      int64    `json:"nbf,omitempty"`
+	Subject        string   `json:"sub,omitempty"`
+	Audience       Audience `json:"aud,omitempty"`
+	Issuer         string   `json:"iss,omitempty"`
+	TokenID        string   `json:"jti,omitempty"`
+}
+
+type introspectionClaims struct {
+	Issuer        string          `json:"iss"`
+	Audience      Audience        `json:"aud"`
+	IssuedAt      int64           `json:"iat"`
+	Introspection json.RawMessage `json:"token_introspection"`
+}
+
+// EncodeIntrospection returns introspection response serialized as signed
+// JWT with "token-introspection+jwt" type, as described by RFC 9701,
+// section 5. Introspection is usually Introspection or a structure
+// embedding it, that is stored in "token_introspection" claim. Audience is
+// identifier of the resource server that requested introspection.
+func EncodeIntrospection(sig Signer, issuer, audience string, introspection interface{}) ([]byte, error) {
+	if sig.Algorithm() == "none" {
+		return nil, errors.New("introspection response must be signed")
+	}
+	b, err := json.Marshal(introspection)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode introspection: %s", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("introspection must be a JSON object: %s", err)
+	}
+	if _, ok := raw["active"].(bool); !ok {
+		return nil, &ClaimError{Claim: "active", Err: ErrMissingClaim}
+	}
+	return Encode(sig, &introspectionClaims{
+		Issuer:        issuer,
+		Audience:      Audience{audience},
+		IssuedAt:      time.Now().Unix(),
+		Introspection: b,
+	}, EncodeWithType(IntrospectionType))
+}
+
+// IntrospectionVerifier validates JWT introspection responses on resource
+// server, as described by RFC 9701, section 6.
+type IntrospectionVerifier struct {
+	// Verifier checks response signature. Usually this is KeySet holding
+	// authorization server keys.
+	Verifier Verifier
+
+	// Issuer is the authorization server issuer identifier that must
+	// exactly match "iss" claim.
+	Issuer string
+
+	// Audience is resource server identifier that must be present in
+	// response audience.
+	Audience string
+
+	// MaxAge, if not zero, is the maximum accepted age of the response.
+	// It allows to use cached responses only as long as they are fresh.
+	MaxAge time.Duration
+
+	// Now is optional (can be nil) function returning current time. It
+	// is useful mostly for testing.
+	Now func() time.Time
+}
+
+// Verify validates introspection response and unpack "token_introspection"
+// claim to given structure, usually Introspection or a structure embedding
+// it. Callers must check whether introspected token is active.
+func (iv *IntrospectionVerifier) Verify(response []byte, introspection interface{}) error {
+	if iv.Verifier.Algorithm() == "none" {
+		return ErrInvalidSigner
+	}
+	now := time.Now
+	if iv.Now != nil {
+		now = iv.Now
+	}
+
+	opts := []DecodeOption{
+		WithClock(now),
+		WithType(IntrospectionType),
+		WithRequiredClaims("iss", "aud", "iat", "token_introspection"),
+		WithIssuer(iv.Issuer),
+		WithAudience(iv.Audience),
+		WithClaimValidator("token_introspection", func(claims map[string]interface{}) error {
+			raw, ok := claims["token_introspection"].(map[string]interface{})
+			if !ok {
+				return errors.New("not an object")
+			}
+			if _, ok := raw["active"].(bool); !ok {
+				return errors.New("active member is required")
+			}
+			return nil
+		}),
+	}
+	if iv.MaxAge != 0 {
+		opts = append(opts, WithClaimValidator("iat", func(claims map[string]interface{}) error {
+			iat, _ := claims["iat"].(float64)
+			if now().Sub(time.Unix(int64(iat), 0)) > iv.MaxAge {
+				return errors.New("introspection response too old")
+			}
+			return nil
+		}))
+	}
+
+	var claims introspectionClaims
+	if err := DecodeClaims(response, iv.Verifier, &claims, opts...); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(claims.Introspection, introspection); err != nil {
+		return fmt.Errorf("cannot JSON decode introspection: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIntrospection(t *testing.T) {
+	const (
+		issuer   = "https://as.example.com"
+		audience = "https://rs.example.com"
+	)
+	sig := RSA256Signer(privRSA, "as-key")
+
+	type customIntrospection struct {
+		Introspection
+		Department string `json:"department"`
+	}
+	response, err := EncodeIntrospection(sig, issuer, audience, &customIntrospection{
+		Introspection: Introspection{
+			Active:   true,
+			Scope:    "read write",
+			ClientID: "client",
+			Subject:  "alice",
+		},
+		Department: "finance",
+	})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+
+	iv := &IntrospectionVerifier{
+		Verifier: sig,
+		Issuer:   issuer,
+		Audience: audience,
+		MaxAge:   time.Minute,
+	}
+	var got customIntrospection
+	if err := iv.Verify(response, &got); err != nil {
+		t.Fatalf("cannot verify: %s", err)
+	}
+	if !got.Active || got.Subject != "alice" || got.Scope != "read write" || got.Department != "finance" {
+		t.Fatalf("unexpected introspection: %+v", got)
+	}
+
+	cases := map[string]struct {
+		verifier  IntrospectionVerifier
+		response  func() ([]byte, error)
+		wantClaim string
+		wantErr   error
+	}{
+		"wrong-issuer": {
+			verifier:  IntrospectionVerifier{Verifier: sig, Issuer: "https://other.example.com", Audience: audience},
+			wantClaim: "iss",
+		},
+		"wrong-audience": {
+			verifier:  IntrospectionVerifier{Verifier: sig, Issuer: issuer, Audience: "https://other.example.com"},
+			wantClaim: "aud",
+		},
+		"too-old": {
+			verifier: IntrospectionVerifier{
+				Verifier: sig,
+				Issuer:   issuer,
+				Audience: audience,
+				MaxAge:   time.Minute,
+				Now:      func() time.Time { return time.Now().Add(time.Hour) },
+			},
+			wantClaim: "iat",
+		},
+		"missing-active": {
+			verifier: IntrospectionVerifier{Verifier: sig, Issuer: issuer, Audience: audience},
+			response: func() ([]byte, error) {
+				return Encode(sig, map[string]interface{}{
+					"iss":                 issuer,
+					"aud":                 audience,
+					"iat":                 time.Now().Unix(),
+					"token_introspection": map[string]interface{}{"sub": "alice"},
+				}, EncodeWithType(IntrospectionType))
+			},
+			wantClaim: "token_introspection",
+		},
+		"array-audience": {
+			verifier: IntrospectionVerifier{Verifier: sig, Issuer: issuer, Audience: audience},
+			response: func() ([]byte, error) {
+				return Encode(sig, map[string]interface{}{
+					"iss":                 issuer,
+					"aud":                 []string{"https://other.example.com", audience},
+					"iat":                 time.Now().Unix(),
+					"token_introspection": map[string]interface{}{"active": true},
+				}, EncodeWithType(IntrospectionType))
+			},
+		},
+		"plain-jwt": {
+			verifier: IntrospectionVerifier{Verifier: sig, Issuer: issuer, Audience: audience},
+			response: func() ([]byte, error) {
+				return Encode(sig, map[string]interface{}{
+					"iss":                 issuer,
+					"aud":                 audience,
+					"iat":                 time.Now().Unix(),
+					"token_introspection": map[string]interface{}{"active": true},
+				})
+			},
+			wantErr: ErrInvalidType,
+		},
+		"wrong-key": {
+			verifier: IntrospectionVerifier{Verifier: RSA256Signer(privRSA, "other-key"), Issuer: issuer, Audience: audience},
+			wantErr:  ErrInvalidSigner,
+		},
+	}
+
+	for tname, tc := range cases {
+		token := response
+		if tc.response != nil {
+			if token, err = tc.response(); err != nil {
+				t.Fatalf("%s: cannot encode: %s", tname, err)
+			}
+		}
+		var got Introspection
+		err := tc.verifier.Verify(token, &got)
+		if tc.wantClaim != "" {
+			var cerr *ClaimError
+			if !errors.As(err, &cerr) || cerr.Claim != tc.wantClaim {
+				t.Errorf("%s: want %q claim error, got %v", tname, tc.wantClaim, err)
+			}
+			continue
+		}
+		if err != tc.wantErr {
+			t.Errorf("%s: want %v, got %v", tname, tc.wantErr, err)
+		}
+	}
+
+	if _, err := EncodeIntrospection(sig, issuer, audience, map[string]interface{}{"sub": "alice"}); err == nil {
+		t.Fatal("want error for introspection without active member")
+	}
+}
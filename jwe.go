@@ -0,0 +1,334 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// Encrypter is the interface implemented by objects that can encrypt content
+// encryption key for the recipient of JWE token.
+type Encrypter interface {
+	// Algorithm returns JWE alg value as defined in RFC 7518
+	// https://tools.ietf.org/html/rfc7518#section-4.1
+	Algorithm() string
+
+	// EncryptKey returns given content encryption key encrypted for the
+	// recipient.
+	EncryptKey(cek []byte) ([]byte, error)
+}
+
+// Decrypter is the interface implemented by objects that can decrypt content
+// encryption key of JWE token. In addition, every Decrypter is also
+// Encrypter.
+type Decrypter interface {
+	Encrypter
+
+	// DecryptKey returns content encryption key decrypted from given
+	// encrypted key.
+	DecryptKey(encryptedKey []byte) ([]byte, error)
+}
+
+var (
+	// ErrDecryption is returned when JWE token cannot be decrypted,
+	// because it was encrypted for a different key or was modified.
+	ErrDecryption = errors.New("cannot decrypt token")
+
+	// ErrUnsupportedEncryption is returned when JWE token is using content
+	// encryption algorithm ("enc") or header parameter that is not
+	// supported.
+	ErrUnsupportedEncryption = errors.New("unsupported encryption")
+)
+
+type rsaOAEPEncrypter struct {
+	keyID string
+	key   *rsa.PublicKey
+}
+
+var _ Encrypter = (*rsaOAEPEncrypter)(nil)
+
+func (e *rsaOAEPEncrypter) Algorithm() string {
+	return "RSA-OAEP-256"
+}
+
+func (e *rsaOAEPEncrypter) KeyID() string {
+	return e.keyID
+}
+
+func (e *rsaOAEPEncrypter) PublicKey() crypto.PublicKey {
+	return e.key
+}
+
+func (e *rsaOAEPEncrypter) EncryptKey(cek []byte) ([]byte, error) {
+	return rsa.EncryptOAEP(sha256.New(), rand.Reader, e.key, cek, nil)
+}
+
+type rsaOAEPDecrypter struct {
+	rsaOAEPEncrypter
+	priv *rsa.PrivateKey
+}
+
+var _ Decrypter = (*rsaOAEPDecrypter)(nil)
+
+func (d *rsaOAEPDecrypter) DecryptKey(encryptedKey []byte) ([]byte, error) {
+	cek, err := rsa.DecryptOAEP(sha256.New(), nil, d.priv, encryptedKey, nil)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	return cek, nil
+}
+
+// RSAOAEP256Encrypter returns encrypter using RSAES OAEP with SHA-256 hash
+// ("RSA-OAEP-256") to encrypt content encryption key for the owner of given
+// public key.
+//
+// keyID is optional (can be empty) argument that is helpful when recipient
+// is using several keys, to determine which key to use during decryption.
+func RSAOAEP256Encrypter(key *rsa.PublicKey, keyID string) Encrypter {
+	return &rsaOAEPEncrypter{keyID: keyID, key: key}
+}
+
+// RSAOAEP256Decrypter returns decrypter using RSAES OAEP with SHA-256 hash
+// ("RSA-OAEP-256") to decrypt content encryption key using given private
+// key.
+func RSAOAEP256Decrypter(key *rsa.PrivateKey, keyID string) Decrypter {
+	return &rsaOAEPDecrypter{
+		rsaOAEPEncrypter: rsaOAEPEncrypter{keyID: keyID, key: &key.PublicKey},
+		priv:             key,
+	}
+}
+
+// jweHeader is the JOSE header of JWE token, as defined in RFC 7516, section
+// 4.1.
+type jweHeader struct {
+	Algorithm   string      `json:"alg"`
+	Encryption  string      `json:"enc"`
+	KeyID       string      `json:"kid,omitempty"`
+	Type        string      `json:"typ,omitempty"`
+	ContentType string      `json:"cty,omitempty"`
+	Compression string      `json:"zip,omitempty"`
+	Critical    interface{} `json:"crit,omitempty"`
+}
+
+// Encrypt returns payload encrypted using JWE compact serialization, as
+// defined in RFC 7516, section 7.1. Payload is encrypted using new random
+// key and given content encryption algorithm, one of "A128GCM", "A256GCM",
+// "A128CBC-HS256" or "A256CBC-HS512". That key is encrypted for the recipient
+// using Encrypter. If Encrypter provides KeyID method, result is attached to
+// header as key id ("kid").
+//
+// contentType is optional (can be empty) "cty" header value. It must be
+// "JWT" when payload is signed JWT, as described by RFC 7519, section 5.2.
+func Encrypt(e Encrypter, encryption, contentType string, payload []byte) ([]byte, error) {
+	ce, ok := contentEncryptions[encryption]
+	if !ok {
+		return nil, ErrUnsupportedEncryption
+	}
+	h := jweHeader{
+		Algorithm:   e.Algorithm(),
+		Encryption:  encryption,
+		ContentType: contentType,
+	}
+	if e, ok := e.(namedKeyHolder); ok {
+		h.KeyID = e.KeyID()
+	}
+	rawHeader, err := json.Marshal(h)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode header: %s", err)
+	}
+
+	cek := make([]byte, ce.keySize)
+	if _, err := rand.Read(cek); err != nil {
+		return nil, fmt.Errorf("cannot generate content encryption key: %s", err)
+	}
+	encryptedKey, err := e.EncryptKey(cek)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encrypt content encryption key: %s", err)
+	}
+	iv := make([]byte, ce.ivSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, fmt.Errorf("cannot generate initialization vector: %s", err)
+	}
+
+	header, _ := encode(rawHeader)
+	ciphertext, tag, err := ce.seal(cek, iv, payload, header)
+	if err != nil {
+		return nil, err
+	}
+	parts := [][]byte{header, nil, nil, nil, nil}
+	for i, b := range [][]byte{encryptedKey, iv, ciphertext, tag} {
+		parts[i+1], _ = encode(b)
+	}
+	return bytes.Join(parts, []byte{'.'}), nil
+}
+
+// Decrypt returns payload of token encrypted using JWE compact serialization.
+// ErrInvalidSigner is returned if token is encrypted using different
+// algorithm than decrypter or if its key ID does not match the one returned
+// by decrypter. ErrDecryption is returned if token was encrypted for
+// different key or was modified. Tokens using compression or critical
+// header parameters are rejected with ErrUnsupportedEncryption.
+func Decrypt(d Decrypter, token []byte) ([]byte, error) {
+	parts := bytes.Split(token, []byte{'.'})
+	if len(parts) != 5 {
+		return nil, ErrMalformedToken
+	}
+	var segments [5][]byte
+	for i, part := range parts {
+		b := make([]byte, strictEnc.DecodedLen(len(part)))
+		n, err := strictEnc.Decode(b, part)
+		if err != nil {
+			return nil, ErrMalformedToken
+		}
+		segments[i] = b[:n]
+	}
+
+	var h jweHeader
+	if err := json.Unmarshal(segments[0], &h); err != nil {
+		return nil, fmt.Errorf("cannot JSON decode header: %s", err)
+	}
+	if h.Compression != "" || h.Critical != nil {
+		return nil, ErrUnsupportedEncryption
+	}
+	ce, ok := contentEncryptions[h.Encryption]
+	if !ok {
+		return nil, ErrUnsupportedEncryption
+	}
+	if h.Algorithm != d.Algorithm() {
+		return nil, ErrInvalidSigner
+	}
+	if d, ok := d.(namedKeyHolder); ok && h.KeyID != "" && h.KeyID != d.KeyID() {
+		return nil, ErrInvalidSigner
+	}
+
+	cek, err := d.DecryptKey(segments[1])
+	if err != nil {
+		return nil, err
+	}
+	if len(cek) != ce.keySize || len(segments[2]) != ce.ivSize {
+		return nil, ErrDecryption
+	}
+	return ce.open(cek, segments[2], segments[3], segments[4], parts[0])
+}
+
+// contentEncryption implements content encryption algorithm as defined in
+// RFC 7518, section 5.1. Additional authenticated data is always the encoded
+// header.
+type contentEncryption struct {
+	keySize int
+	ivSize  int
+	seal    func(cek, iv, plaintext, aad []byte) (ciphertext, tag []byte, err error)
+	open    func(cek, iv, ciphertext, tag, aad []byte) ([]byte, error)
+}
+
+var contentEncryptions = map[string]contentEncryption{
+	"A128GCM":       gcmEncryption(16),
+	"A256GCM":       gcmEncryption(32),
+	"A128CBC-HS256": cbcHMACEncryption(16, sha256.New),
+	"A256CBC-HS512": cbcHMACEncryption(32, sha512.New),
+}
+
+// gcmEncryption returns AES GCM content encryption as defined in RFC 7518,
+// section 5.3.
+func gcmEncryption(keySize int) contentEncryption {
+	newGCM := func(cek []byte) (cipher.AEAD, error) {
+		block, err := aes.NewCipher(cek)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	}
+	return contentEncryption{
+		keySize: keySize,
+		ivSize:  12,
+		seal: func(cek, iv, plaintext, aad []byte) ([]byte, []byte, error) {
+			aead, err := newGCM(cek)
+			if err != nil {
+				return nil, nil, err
+			}
+			sealed := aead.Seal(nil, iv, plaintext, aad)
+			n := len(sealed) - aead.Overhead()
+			return sealed[:n], sealed[n:], nil
+		},
+		open: func(cek, iv, ciphertext, tag, aad []byte) ([]byte, error) {
+			aead, err := newGCM(cek)
+			if err != nil {
+				return nil, err
+			}
+			if len(tag) != aead.Overhead() {
+				return nil, ErrDecryption
+			}
+			sealed := append(append([]byte{}, ciphertext...), tag...)
+			plaintext, err := aead.Open(nil, iv, sealed, aad)
+			if err != nil {
+				return nil, ErrDecryption
+			}
+			return plaintext, nil
+		},
+	}
+}
+
+// cbcHMACEncryption returns AES CBC content encryption with HMAC
+// authentication as defined in RFC 7518, section 5.2. Content encryption key
+// is twice the AES key size, its first half is the MAC key and authentication
+// tag is truncated to the AES key size.
+func cbcHMACEncryption(keySize int, newHash func() hash.Hash) contentEncryption {
+	authTag := func(macKey, aad, iv, ciphertext []byte) []byte {
+		mac := hmac.New(newHash, macKey)
+		mac.Write(aad)
+		mac.Write(iv)
+		mac.Write(ciphertext)
+		var al [8]byte
+		binary.BigEndian.PutUint64(al[:], uint64(len(aad))*8)
+		mac.Write(al[:])
+		return mac.Sum(nil)[:keySize]
+	}
+	return contentEncryption{
+		keySize: 2 * keySize,
+		ivSize:  aes.BlockSize,
+		seal: func(cek, iv, plaintext, aad []byte) ([]byte, []byte, error) {
+			block, err := aes.NewCipher(cek[keySize:])
+			if err != nil {
+				return nil, nil, err
+			}
+			// PKCS #7 padding
+			pad := aes.BlockSize - len(plaintext)%aes.BlockSize
+			ciphertext := append(append([]byte{}, plaintext...), bytes.Repeat([]byte{byte(pad)}, pad)...)
+			cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+			return ciphertext, authTag(cek[:keySize], aad, iv, ciphertext), nil
+		},
+		open: func(cek, iv, ciphertext, tag, aad []byte) ([]byte, error) {
+			// ciphertext must be authenticated before it is decrypted
+			if !hmac.Equal(tag, authTag(cek[:keySize], aad, iv, ciphertext)) {
+				return nil, ErrDecryption
+			}
+			if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+				return nil, ErrDecryption
+			}
+			block, err := aes.NewCipher(cek[keySize:])
+			if err != nil {
+				return nil, err
+			}
+			plaintext := make([]byte, len(ciphertext))
+			cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+			pad := int(plaintext[len(plaintext)-1])
+			if pad == 0 || pad > aes.BlockSize || subtle.ConstantTimeCompare(
+				plaintext[len(plaintext)-pad:], bytes.Repeat([]byte{byte(pad)}, pad)) != 1 {
+				return nil, ErrDecryption
+			}
+			return plaintext[:len(plaintext)-pad], nil
+		},
+	}
+}
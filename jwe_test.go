@@ -0,0 +1,203 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := rfc7520RSAKey(t)
+	payload := []byte(`{"iss":"s6BhdRkqt3"}`)
+
+	for _, encryption := range []string{"A128GCM", "A256GCM", "A128CBC-HS256", "A256CBC-HS512"} {
+		t.Run(encryption, func(t *testing.T) {
+			token, err := Encrypt(RSAOAEP256Encrypter(&key.PublicKey, "enc-key"), encryption, "JWT", payload)
+			if err != nil {
+				t.Fatalf("cannot encrypt: %s", err)
+			}
+			if n := bytes.Count(token, []byte{'.'}); n != 4 {
+				t.Fatalf("want 5 parts, got %d", n+1)
+			}
+			got, err := Decrypt(RSAOAEP256Decrypter(key, "enc-key"), token)
+			if err != nil {
+				t.Fatalf("cannot decrypt: %s", err)
+			}
+			if !bytes.Equal(got, payload) {
+				t.Fatalf("want %q payload, got %q", payload, got)
+			}
+		})
+	}
+}
+
+func TestDecryptInterop(t *testing.T) {
+	// tokens encrypted for RFC 7520, section 3.4 key by independent
+	// implementation (Python cryptography package)
+	cases := map[string]string{
+		"A256GCM": "eyJhbGciOiJSU0EtT0FFUC0yNTYiLCJlbmMiOiJBMjU2R0NNIiwia2lkIjoiYmls" +
+			"Ym8uYmFnZ2luc0Bob2JiaXRvbi5leGFtcGxlIn0" +
+			"." +
+			"WKBHHAbywuTDlnFwP7GeBwJvg7guJmwB-GbDityFK29et3oJYovPxaa3HS2QfX-z" +
+			"ZnsOJY0oCxCmwKevTdk0wEIty2R1zOUw6RSpC7x93ysqstnYEi8ibJbS14LfV5zc" +
+			"zyjrpYuFJDse92PT2vHPq9PM1fAAgZu3vaUCuDmbn8oPdXI8vAJS3GDH-qmZh-wK" +
+			"GZst_t_wXU6i8GtJNW9Wbzz_ztqzAnO1rKesiY6ogRo0M_HdHi-8WQxw5StHVeky" +
+			"RZzqd1Y8VM2EPMrkefdoR6uGhkPoqUTYvgyRKGUomObQUPXmYf6hgsC82DNbyENl" +
+			"TeWkZrpG-Fk3G8d88tPY4A" +
+			"." +
+			"Du1-9atzu6evb5KI" +
+			"." +
+			"4QUQJSaYZO9auXlXiDhEIMeKLgtkOZgEUy6VCpk6l9kj2ItTf86Qt-mEdYWZ4Ags" +
+			"mtGTYxhxtw" +
+			"." +
+			"05m7Mct4ODKMxj-5MS6eeA",
+		"A128CBC-HS256": "eyJhbGciOiJSU0EtT0FFUC0yNTYiLCJlbmMiOiJBMTI4Q0JDLUhTMjU2In0" +
+			"." +
+			"YK9fzXS8YKTOw7ka0bW1mO8LwLZjCOX26XzB4Kz16z4F8kw_CBsNcE2MnNTVPDz6" +
+			"-YPre-jczKMrx4IcawEYj5ZLHMRteqQFgEuwERGZIALJg6QzaPGFFrDoc663V37g" +
+			"UAfN3akFK11atXBUdZ57bsCf0JEhn_laRvLyV8liDeRNCsZtb3CW6b6p6LxbHE9k" +
+			"YZE7U9YliRq0EWTmo_ApfBRPVtRSqo7HlyoqnDYZjuA4JXz04F1mME85kft_WZxy" +
+			"BpsyR5DLlpb9ykJhK6CraZctGrqN9bi-TkAIoHe0eDYPFlXciYLN5IN9Ugl8yTqd" +
+			"LfzazsVPLxmvFSdGFKe8TQ" +
+			"." +
+			"iAqyuiEkSvXqUHTMDHx6lg" +
+			"." +
+			"XlCS7t26AvkuXrfc8DLfFB0EtUqColjeO0BA9_wnFtH6CIHmrfIXyqeapKHOIzH1" +
+			"GMYw2giGRhydQKc5rheIQA" +
+			"." +
+			"uZc9p0u7dIlmSkfyicMjNQ",
+	}
+
+	key := rfc7520RSAKey(t)
+	for tname, token := range cases {
+		got, err := Decrypt(RSAOAEP256Decrypter(key, "bilbo.baggins@hobbiton.example"), []byte(token))
+		if err != nil {
+			t.Errorf("%s: cannot decrypt: %s", tname, err)
+			continue
+		}
+		if want := `{"iss":"s6BhdRkqt3","aud":"https://server.example.com"}`; string(got) != want {
+			t.Errorf("%s: want %q payload, got %q", tname, want, got)
+		}
+	}
+}
+
+func TestDecryptInvalid(t *testing.T) {
+	key := rfc7520RSAKey(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate key: %s", err)
+	}
+	encrypt := func(encryption string) []byte {
+		token, err := Encrypt(RSAOAEP256Encrypter(&key.PublicKey, "enc-key"), encryption, "", []byte("payload"))
+		if err != nil {
+			t.Fatalf("cannot encrypt: %s", err)
+		}
+		return token
+	}
+	// replace returns token with given part replaced
+	replace := func(token []byte, part int, value string) []byte {
+		parts := bytes.Split(token, []byte{'.'})
+		parts[part], _ = encode([]byte(value))
+		return bytes.Join(parts, []byte{'.'})
+	}
+	// flip returns token with the first byte of given part modified
+	flip := func(token []byte, part int) []byte {
+		parts := bytes.Split(token, []byte{'.'})
+		b := make([]byte, enc.DecodedLen(len(parts[part])))
+		n, _ := enc.Decode(b, parts[part])
+		b[0] ^= 1
+		parts[part], _ = encode(b[:n])
+		return bytes.Join(parts, []byte{'.'})
+	}
+
+	cases := map[string]struct {
+		token     []byte
+		decrypter Decrypter
+		wantErr   error
+	}{
+		"other-key": {
+			token:     encrypt("A128GCM"),
+			decrypter: RSAOAEP256Decrypter(otherKey, "enc-key"),
+			wantErr:   ErrDecryption,
+		},
+		"other-key-id": {
+			token:     encrypt("A128GCM"),
+			decrypter: RSAOAEP256Decrypter(key, "other-key"),
+			wantErr:   ErrInvalidSigner,
+		},
+		"gcm-ciphertext": {
+			token:     flip(encrypt("A256GCM"), 3),
+			decrypter: RSAOAEP256Decrypter(key, "enc-key"),
+			wantErr:   ErrDecryption,
+		},
+		"gcm-tag": {
+			token:     flip(encrypt("A256GCM"), 4),
+			decrypter: RSAOAEP256Decrypter(key, "enc-key"),
+			wantErr:   ErrDecryption,
+		},
+		"cbc-ciphertext": {
+			token:     flip(encrypt("A128CBC-HS256"), 3),
+			decrypter: RSAOAEP256Decrypter(key, "enc-key"),
+			wantErr:   ErrDecryption,
+		},
+		"cbc-iv": {
+			token:     flip(encrypt("A256CBC-HS512"), 2),
+			decrypter: RSAOAEP256Decrypter(key, "enc-key"),
+			wantErr:   ErrDecryption,
+		},
+		"header": {
+			token:     replace(encrypt("A128GCM"), 0, `{"alg":"RSA-OAEP-256","enc":"A128GCM","x":1}`),
+			decrypter: RSAOAEP256Decrypter(key, "enc-key"),
+			wantErr:   ErrDecryption,
+		},
+		"other-algorithm": {
+			token:     replace(encrypt("A128GCM"), 0, `{"alg":"RSA-OAEP","enc":"A128GCM"}`),
+			decrypter: RSAOAEP256Decrypter(key, "enc-key"),
+			wantErr:   ErrInvalidSigner,
+		},
+		"other-encryption": {
+			token:     replace(encrypt("A128GCM"), 0, `{"alg":"RSA-OAEP-256","enc":"A256GCM"}`),
+			decrypter: RSAOAEP256Decrypter(key, "enc-key"),
+			wantErr:   ErrDecryption,
+		},
+		"unknown-encryption": {
+			token:     replace(encrypt("A128GCM"), 0, `{"alg":"RSA-OAEP-256","enc":"A192GCM"}`),
+			decrypter: RSAOAEP256Decrypter(key, "enc-key"),
+			wantErr:   ErrUnsupportedEncryption,
+		},
+		"compressed": {
+			token:     replace(encrypt("A128GCM"), 0, `{"alg":"RSA-OAEP-256","enc":"A128GCM","zip":"DEF"}`),
+			decrypter: RSAOAEP256Decrypter(key, "enc-key"),
+			wantErr:   ErrUnsupportedEncryption,
+		},
+		"critical": {
+			token:     replace(encrypt("A128GCM"), 0, `{"alg":"RSA-OAEP-256","enc":"A128GCM","crit":["exp"]}`),
+			decrypter: RSAOAEP256Decrypter(key, "enc-key"),
+			wantErr:   ErrUnsupportedEncryption,
+		},
+		"signed-token": {
+			token:     []byte("eyJhbGciOiJIUzI1NiJ9.e30.c2ln"),
+			decrypter: RSAOAEP256Decrypter(key, "enc-key"),
+			wantErr:   ErrMalformedToken,
+		},
+		"padded": {
+			token:     append(encrypt("A128GCM"), '='),
+			decrypter: RSAOAEP256Decrypter(key, "enc-key"),
+			wantErr:   ErrMalformedToken,
+		},
+	}
+
+	for tname, tc := range cases {
+		if _, err := Decrypt(tc.decrypter, tc.token); !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: want %v error, got %v", tname, tc.wantErr, err)
+		}
+	}
+}
+
+func TestEncryptUnsupported(t *testing.T) {
+	key := rfc7520RSAKey(t)
+	if _, err := Encrypt(RSAOAEP256Encrypter(&key.PublicKey, ""), "A192GCM", "", []byte("payload")); err != ErrUnsupportedEncryption {
+		t.Fatalf("want ErrUnsupportedEncryption, got %v", err)
+	}
+}
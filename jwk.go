@@ -0,0 +1,171 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// JWK is JSON Web Key as defined in RFC 7517. Only public key parameters are
+// supported, so that it is always safe to publish.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+
+	// RSA public key parameters as defined in RFC 7518, section 6.3.1
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+}
+
+// JWKSet is JSON Web Key Set as defined in RFC 7517, section 5.
+//
+// JWKSet implements KeySet, so it can be used directly to decode tokens
+// signed by any of the contained keys.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+var _ KeySet = (*JWKSet)(nil)
+
+type publicKeyHolder interface {
+	PublicKey() crypto.PublicKey
+}
+
+// PublicJWK returns public key of given verifier, serialized as JSON Web Key.
+// ErrNoPublicKey is returned if verifier does not provide public key, which
+// is always the case for symmetric algorithms.
+func PublicJWK(v Verifier) (JWK, error) {
+	holder, ok := v.(publicKeyHolder)
+	if !ok {
+		return JWK{}, ErrNoPublicKey
+	}
+
+	var keyID string
+	if v, ok := v.(namedKeyHolder); ok {
+		keyID = v.KeyID()
+	}
+
+	switch key := holder.PublicKey().(type) {
+	case *rsa.PublicKey:
+		n, _ := encode(key.N.Bytes())
+		e, _ := encode(big.NewInt(int64(key.E)).Bytes())
+		return JWK{
+			KeyType:   "RSA",
+			Use:       "sig",
+			Algorithm: v.Algorithm(),
+			KeyID:     keyID,
+			N:         string(n),
+			E:         string(e),
+		}, nil
+	default:
+		return JWK{}, ErrUnsupportedKey
+	}
+}
+
+// Thumbprint returns JWK thumbprint of this key as defined in RFC 7638,
+// computed using SHA-256 and base64 encoded.
+func (k JWK) Thumbprint() (string, error) {
+	var members interface{}
+	switch k.KeyType {
+	case "RSA":
+		// required members in lexicographic order, see RFC 7638 section 3.2
+		members = struct {
+			E       string `json:"e"`
+			KeyType string `json:"kty"`
+			N       string `json:"n"`
+		}{k.E, k.KeyType, k.N}
+	default:
+		return "", ErrUnsupportedKey
+	}
+	b, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	thumbprint, _ := encode(sum[:])
+	return string(thumbprint), nil
+}
+
+// Verifier returns verifier using this key and given algorithm. If key
+// declares algorithm, it must be the same as requested one. ErrWeakKey is
+// returned for keys that are too short, unless AllowWeakKeys option is used.
+func (k JWK) Verifier(alg string, opts ...KeyOption) (Verifier, error) {
+	if k.Algorithm != "" && k.Algorithm != alg {
+		return nil, ErrInvalidSigner
+	}
+	if k.Use != "" && k.Use != "sig" {
+		return nil, ErrInvalidSigner
+	}
+
+	switch k.KeyType {
+	case "RSA":
+		newVerifier, ok := rsaVerifiers[alg]
+		if !ok {
+			return nil, ErrUnsupportedKey
+		}
+		n, err := decodeSegment([]byte(k.N), false)
+		if err != nil {
+			return nil, fmt.Errorf("cannot base64 decode modulus: %s", err)
+		}
+		e, err := decodeSegment([]byte(k.E), false)
+		if err != nil {
+			return nil, fmt.Errorf("cannot base64 decode exponent: %s", err)
+		}
+		exp := new(big.Int).SetBytes(e)
+		if len(n) == 0 || !exp.IsInt64() || exp.Int64() < 2 || exp.Int64() > 1<<31-1 {
+			return nil, ErrUnsupportedKey
+		}
+		return newVerifier(&rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(exp.Int64()),
+		}, opts...)
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+// Algorithm returns empty string, because key set is not bound to a single
+// algorithm.
+func (s *JWKSet) Algorithm() string {
+	return ""
+}
+
+// Verify returns nil if signature can be verified by any key from the set
+// that declares its algorithm.
+func (s *JWKSet) Verify(signature, data []byte) error {
+	for _, k := range s.Keys {
+		v, err := k.Verifier(k.Algorithm)
+		if err != nil {
+			continue
+		}
+		if err := v.Verify(signature, data); err == nil {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// Lookup returns verifier for the key with given ID and algorithm. If key ID
+// is empty, the first key supporting given algorithm is used.
+func (s *JWKSet) Lookup(alg, keyID string) (Verifier, error) {
+	for _, k := range s.Keys {
+		if k.KeyID != keyID && keyID != "" {
+			continue
+		}
+		if v, err := k.Verifier(alg); err == nil {
+			return v, nil
+		}
+	}
+	return nil, ErrInvalidSigner
+}
+
+var rsaVerifiers = map[string]func(*rsa.PublicKey, ...KeyOption) (Verifier, error){
+	"RS256": NewRSA256Verifier,
+	"RS384": NewRSA384Verifier,
+	"RS512": NewRSA512Verifier,
+}
@@ -2,10 +2,13 @@ package jwt
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -32,23 +35,87 @@ type Signer interface {
 	Sign(data []byte) ([]byte, error)
 }
 
+// KeySet is the interface implemented by verifiers holding more than one key,
+// for example during signing key rotation. When used for decoding, the key is
+// selected using algorithm and key ID declared by the token header.
+type KeySet interface {
+	Verifier
+
+	// Lookup returns verifier for given algorithm and key ID.
+	// ErrInvalidSigner is returned if no matching key is known.
+	Lookup(alg, keyID string) (Verifier, error)
+}
+
+// ContextSigner is the interface implemented by signers that can use context
+// to cancel signing or to carry its deadline, for example because signature
+// is computed by remote service.
+type ContextSigner interface {
+	Signer
+
+	// SignContext returns signature computed for given data.
+	SignContext(ctx context.Context, data []byte) ([]byte, error)
+}
+
+// ContextVerifier is the interface implemented by verifiers that can use
+// context to cancel verification or to carry its deadline.
+type ContextVerifier interface {
+	Verifier
+
+	// VerifyContext returns error if signature computed for given data
+	// is different than expected values.
+	VerifyContext(ctx context.Context, signature, data []byte) error
+}
+
+// ContextKeySet is the interface implemented by key sets that can use context
+// when looking up keys, for example because keys are fetched from remote
+// location.
+type ContextKeySet interface {
+	KeySet
+
+	// LookupContext returns verifier for given algorithm and key ID.
+	LookupContext(ctx context.Context, alg, keyID string) (Verifier, error)
+}
+
 // Encode return claims serialized as signed JWT token. If Signer provides
 // KeyID method, result is attached to header as signature key id ("kid").
-func Encode(sig Signer, claims interface{}) ([]byte, error) {
+// If Signer provides Current method (like RotatingSigner), token is signed
+// using returned signer. Header can be extended using options.
+func Encode(sig Signer, claims interface{}, opts ...EncodeOption) ([]byte, error) {
+	return EncodeContext(context.Background(), sig, claims, opts...)
+}
+
+// EncodeContext is like Encode, but if signer implements ContextSigner, given
+// context is used for signing.
+func EncodeContext(ctx context.Context, sig Signer, claims interface{}, opts ...EncodeOption) ([]byte, error) {
+	conf := encodeOptions{typ: "JWT"}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	// resolve signer holding several keys only once, so that header and
+	// signature are always computed using the same key
+	if s, ok := sig.(activeSigner); ok {
+		current, err := s.Current()
+		if err != nil {
+			return nil, err
+		}
+		sig = current
+	}
+
 	var keyID string
 	if s, ok := sig.(namedKeyHolder); ok {
 		keyID = s.KeyID()
 	}
 
-	header, err := encodeJSON(struct {
+	header, err := encodeHeader(struct {
 		Type      string `json:"typ"`
 		Algorithm string `json:"alg"`
 		KeyID     string `json:"kid,omitempty"`
 	}{
-		Type:      "JWT",
+		Type:      conf.typ,
 		Algorithm: sig.Algorithm(),
 		KeyID:     keyID,
-	})
+	}, conf.header)
 	if err != nil {
 		return nil, fmt.Errorf("cannot encode header: %s", err)
 	}
@@ -61,9 +128,14 @@ func Encode(sig Signer, claims interface{}) ([]byte, error) {
 	token := append(header, '.')
 	token = append(token, content...)
 
-	signature, err := sig.Sign(token)
+	var signature []byte
+	if s, ok := sig.(ContextSigner); ok {
+		signature, err = s.SignContext(ctx, token)
+	} else {
+		signature, err = sig.Sign(token)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("cannot sign: %s", err)
+		return nil, fmt.Errorf("cannot sign: %w", err)
 	}
 	signature, err = encode(signature)
 	if err != nil {
@@ -75,10 +147,79 @@ func Encode(sig Signer, claims interface{}) ([]byte, error) {
 	return token, nil
 }
 
+// EncodeOption configures header of the token created by Encode.
+type EncodeOption func(*encodeOptions)
+
+type encodeOptions struct {
+	typ    string
+	header map[string]interface{}
+}
+
+// EncodeWithType returns option that sets token type ("typ") header to given
+// value instead of "JWT".
+func EncodeWithType(typ string) EncodeOption {
+	return func(o *encodeOptions) {
+		o.typ = typ
+	}
+}
+
+// EncodeWithHeader returns option that adds given parameter to the token
+// header. Parameters set by Encode ("typ", "alg" and "kid") cannot be
+// changed this way.
+func EncodeWithHeader(name string, value interface{}) EncodeOption {
+	return func(o *encodeOptions) {
+		if o.header == nil {
+			o.header = make(map[string]interface{})
+		}
+		o.header[name] = value
+	}
+}
+
+// encodeHeader serialize given header and extra parameters into JSON object
+// and return it's base64 representation. Extra parameters are appended in
+// alphabetical order after base header fields.
+func encodeHeader(base interface{}, extra map[string]interface{}) ([]byte, error) {
+	b, err := json.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(extra))
+	for name := range extra {
+		switch name {
+		case "typ", "alg", "kid":
+			return nil, fmt.Errorf("%q header parameter cannot be set", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b = b[:len(b)-1]
+	for _, name := range names {
+		n, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(extra[name])
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+		b = append(b, n...)
+		b = append(b, ':')
+		b = append(b, v...)
+	}
+	b = append(b, '}')
+	return encode(b)
+}
+
 type namedKeyHolder interface {
 	KeyID() string
 }
 
+type activeSigner interface {
+	Current() (Signer, error)
+}
+
 // encodeJSON encode serialize given data into JSON and return it's base64
 // representation with base64 padding removed.
 func encodeJSON(jsonable interface{}) ([]byte, error) {
@@ -92,91 +233,363 @@ func encodeJSON(jsonable interface{}) ([]byte, error) {
 func encode(b []byte) ([]byte, error) {
 	b64 := make([]byte, enc.EncodedLen(len(b)))
 	enc.Encode(b64, b)
-	b64 = bytes.TrimRight(b64, "=")
 	return b64, nil
 }
 
 // DecodeClaims test JWT token signature and if valid, unpack claims to given
-// structure.
+// structure. If claims is nil, only validation is done. As with
+// json.Unmarshal, claims must be a pointer, otherwise error is returned.
 //
 // Validation is on purpose part of this function, so that it's not possible to
-// extract claims from invalid tokens.
-func DecodeClaims(token []byte, v Verifier, claims interface{}) error {
-	chunks := bytes.Split(token, []byte("."))
-	if len(chunks) != 3 {
-		return ErrMalformedToken
+// extract claims from invalid tokens. Additional validation can be enabled
+// using options.
+func DecodeClaims(token []byte, v Verifier, claims interface{}, opts ...DecodeOption) error {
+	return DecodeClaimsContext(context.Background(), token, v, claims, opts...)
+}
+
+// DecodeClaimsContext is like DecodeClaims, but given context is used for
+// key lookup if verifier implements ContextKeySet and for signature
+// verification if verifier implements ContextVerifier.
+func DecodeClaimsContext(ctx context.Context, token []byte, v Verifier, claims interface{}, opts ...DecodeOption) error {
+	conf := decodeOptions{now: time.Now, limits: DefaultLimits}
+	if len(opts) != 0 {
+		c := &decodeOptions{now: time.Now, limits: DefaultLimits}
+		for _, opt := range opts {
+			opt(c)
+		}
+		conf = *c
+	}
+	if conf.hooks == nil {
+		return decodeClaims(ctx, token, v, claims, conf, nil)
 	}
 
-	// create big enough buffer
-	buf := make([]byte, maxlen(chunks)+3)
-	var b []byte
+	start := time.Now()
+	var info decodeInfo
+	err := decodeClaims(ctx, token, v, claims, conf, &info)
+	conf.hooks.TokenDecoded(ctx, DecodeEvent{
+		Algorithm: info.algorithm,
+		KeyID:     info.keyID,
+		Issuer:    info.issuer,
+		Duration:  time.Since(start),
+		Err:       err,
+		Category:  ErrorCategory(err),
+	})
+	return err
+}
+
+// decodeInfo holds token details reported to hooks. Values are copied out of
+// the token only if hooks are used, so that decoding does not allocate
+// otherwise.
+type decodeInfo struct {
+	algorithm string
+	keyID     string
+	issuer    string
+}
+
+func decodeClaims(ctx context.Context, token []byte, v Verifier, claims interface{}, conf decodeOptions, info *decodeInfo) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if conf.limits.MaxTokenSize > 0 && len(token) > conf.limits.MaxTokenSize {
+		return ErrTokenTooLarge
+	}
+	parts, err := splitToken(token)
+	if err != nil {
+		return err
+	}
+
+	// decode all parts into single, big enough buffer
+	buf := getBuffer(decodedLen(parts.header) + decodedLen(parts.claims) + decodedLen(parts.signature))
+	defer putBuffer(buf)
+	b := *buf
 
 	// decode header
-	if n, err := enc.Decode(buf, fixPadding(chunks[0])); err != nil {
-		return fmt.Errorf("cannot base64 decode header: %s", err)
-	} else {
-		b = buf[:n]
+	header, err := decodeInto(b, parts.header, conf.strict)
+	if err != nil {
+		return &SegmentError{Segment: "header", Err: err}
 	}
-	var header struct {
-		Algorithm string `json:"alg"`
-		KeyID     string `json:"kid"`
+	b = b[len(header):]
+	if conf.limits.MaxHeaderSize > 0 && len(header) > conf.limits.MaxHeaderSize {
+		return ErrHeaderTooLarge
+	}
+	var alg, keyID, typ []byte
+	err = conf.limits.scanObject(header, func(name, value []byte) error {
+		var err error
+		switch string(name) {
+		case "alg":
+			alg, err = jsonString(value)
+		case "kid":
+			keyID, err = jsonString(value)
+		case "typ":
+			typ, err = jsonString(value)
+		}
+		return err
+	})
+	if err != nil {
+		return jsonError("header", err)
 	}
-	if err := json.Unmarshal(b, &header); err != nil {
-		return fmt.Errorf("cannot JSON decode header: %s", err)
+	if info != nil {
+		info.algorithm = eventString(alg)
+		info.keyID = eventString(keyID)
 	}
 
 	// decode claims
-	if n, err := enc.Decode(buf, fixPadding(chunks[1])); err != nil {
-		return fmt.Errorf("cannot base64 decode claims: %s", err)
-	} else {
-		b = buf[:n]
+	payload, err := decodeInto(b, parts.claims, conf.strict)
+	if err != nil {
+		return &SegmentError{Segment: "claims", Err: err}
 	}
-	if err := json.Unmarshal(b, &claims); err != nil {
-		return fmt.Errorf("cannot JSON decode claims: %s", err)
+	b = b[len(payload):]
+	if conf.limits.MaxClaimsSize > 0 && len(payload) > conf.limits.MaxClaimsSize {
+		return ErrClaimsTooLarge
 	}
-	// decode extra claims that will be used later for the validation
+	// claims are scanned first, so that limits are enforced before any
+	// allocation is done by JSON decoding
 	var lifetime struct {
-		ExpirationTime int64 `json:"exp"`
-		NotBefore      int64 `json:"nbf"`
+		expirationTime int64
+		notBefore      int64
+		tokenID        []byte
+	}
+	err = conf.limits.scanObject(payload, func(name, value []byte) error {
+		var err error
+		switch {
+		case claimNameIs(name, "exp"):
+			lifetime.expirationTime, err = numericDate(value)
+		case claimNameIs(name, "nbf"):
+			lifetime.notBefore, err = numericDate(value)
+		case claimNameIs(name, "jti"):
+			lifetime.tokenID, err = jsonString(value)
+		case claimNameIs(name, "iss"):
+			if info != nil {
+				var iss []byte
+				if iss, err = jsonString(value); err == nil {
+					info.issuer = eventString(iss)
+				}
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return jsonError("claims", err)
 	}
-	if err := json.Unmarshal(b, &lifetime); err != nil {
-		return fmt.Errorf("cannot JSON decode claims: %s", err)
+	if claims != nil {
+		if err := json.Unmarshal(payload, claims); err != nil {
+			return fmt.Errorf("cannot JSON decode claims: %s", err)
+		}
+	}
+	// raw claims are needed only by custom validators
+	var rawClaims map[string]interface{}
+	if len(conf.required) != 0 || len(conf.validators) != 0 {
+		if rawClaims, err = decodeClaimsMap(payload); err != nil {
+			return fmt.Errorf("cannot JSON decode claims: %s", err)
+		}
 	}
 
-	if header.Algorithm != v.Algorithm() {
+	if ks, ok := v.(ContextKeySet); ok {
+		key, err := ks.LookupContext(ctx, string(alg), string(keyID))
+		if err != nil {
+			return err
+		}
+		v = key
+	} else if ks, ok := v.(KeySet); ok {
+		key, err := ks.Lookup(string(alg), string(keyID))
+		if err != nil {
+			return err
+		}
+		v = key
+	}
+	if string(alg) != v.Algorithm() {
 		return ErrInvalidSigner
 	}
 	// if header does contain key id and our validator does provide one as
 	// well, match those two, because they must be the same
-	if v, ok := v.(namedKeyHolder); ok && header.KeyID != "" {
-		if v.KeyID() != header.KeyID {
+	if v, ok := v.(namedKeyHolder); ok && len(keyID) != 0 {
+		if v.KeyID() != string(keyID) {
 			return ErrInvalidSigner
 		}
 	}
 
+	if conf.typ != "" && !typeMatches(typ, conf.typ) {
+		return ErrInvalidType
+	}
+
 	// validate signature
-	if n, err := enc.Decode(buf, fixPadding(chunks[2])); err != nil {
-		return fmt.Errorf("cannot base64 decode signature: %s", err)
+	signature, err := decodeInto(b, parts.signature, conf.strict)
+	if err != nil {
+		return &SegmentError{Segment: "signature", Err: err}
+	}
+	if cv, ok := v.(ContextVerifier); ok {
+		err = cv.VerifyContext(ctx, signature, parts.signingInput)
 	} else {
-		b = buf[:n]
+		err = v.Verify(signature, parts.signingInput)
 	}
-	beforeSign := token[:len(token)-len(chunks[2])-1]
-	if err := v.Verify(b, beforeSign); err != nil {
+	if err != nil {
 		return err
 	}
 
 	// make sure token is still valid
-	now := time.Now()
-	if lifetime.ExpirationTime != 0 && lifetime.ExpirationTime < now.Unix() {
+	now := conf.now()
+	if lifetime.expirationTime != 0 && lifetime.expirationTime < now.Unix() {
 		return ErrExpired
 	}
-	if lifetime.NotBefore != 0 && lifetime.NotBefore > now.Unix() {
+	if lifetime.notBefore != 0 && lifetime.notBefore > now.Unix() {
 		return ErrNotReady
 	}
 
+	if err := validateClaims(rawClaims, conf.required, conf.validators); err != nil {
+		return err
+	}
+
+	// token ID is consumed only once token is known to be valid, so that
+	// invalid tokens cannot burn it
+	if conf.replay != nil {
+		if len(lifetime.tokenID) == 0 {
+			return ErrMissingTokenID
+		}
+		var exp time.Time
+		if lifetime.expirationTime != 0 {
+			exp = time.Unix(lifetime.expirationTime, 0)
+		}
+		if err := conf.replay.Use(string(lifetime.tokenID), exp); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// claimNameIs returns true if member name matches given claim name the same
+// way json.Unmarshal matches struct fields, that is ignoring case. Otherwise
+// for example "EXP" member would set the expiration time of decoded claims,
+// without being checked.
+func claimNameIs(name []byte, claim string) bool {
+	return bytes.EqualFold(name, []byte(claim))
+}
+
+func decodeClaimsMap(b []byte) (map[string]interface{}, error) {
+	var claims map[string]interface{}
+	err := json.Unmarshal(b, &claims)
+	return claims, err
+}
+
+// DecodeOption configures additional validation done by DecodeClaims.
+type DecodeOption func(*decodeOptions)
+
+type decodeOptions struct {
+	now        func() time.Time
+	typ        string
+	replay     ReplayStore
+	required   []string
+	validators []claimValidator
+	strict     bool
+	limits     Limits
+	hooks      Hooks
+}
+
+// Limits restricts size and complexity of decoded tokens, protecting from
+// resource exhaustion by untrusted input. Zero value of any size or depth
+// limit means no limit.
+type Limits struct {
+	// MaxTokenSize is the maximum length of the encoded token.
+	MaxTokenSize int
+
+	// MaxHeaderSize and MaxClaimsSize are the maximum lengths of decoded
+	// header and claims JSON.
+	MaxHeaderSize int
+	MaxClaimsSize int
+
+	// MaxDepth is the maximum nesting depth of header and claims JSON
+	// values, counting the top level object.
+	MaxDepth int
+
+	// AllowDuplicateMembers disables rejecting of header and claims with
+	// repeated member names, as recommended by RFC 7515, section 5.2. If
+	// allowed, the last value is used.
+	AllowDuplicateMembers bool
+}
+
+// DefaultLimits are limits used unless WithLimits option is provided.
+var DefaultLimits = Limits{
+	MaxTokenSize:  64 << 10,
+	MaxHeaderSize: 16 << 10,
+	MaxClaimsSize: 32 << 10,
+	MaxDepth:      32,
+}
+
+// WithLimits returns option that makes decoding use given limits instead of
+// DefaultLimits.
+func WithLimits(limits Limits) DecodeOption {
+	return func(o *decodeOptions) {
+		o.limits = limits
+	}
+}
+
+// scanObject walks members of JSON object, enforcing depth limit and
+// uniqueness of member names.
+func (l *Limits) scanObject(data []byte, fn func(name, value []byte) error) error {
+	return scanObjectLimited(data, l.MaxDepth, !l.AllowDuplicateMembers, fn)
+}
+
+// jsonError returns error describing failed JSON decoding of given token
+// part. Errors of exceeded limits are returned unchanged.
+func jsonError(part string, err error) error {
+	if err == ErrNestingTooDeep || err == ErrDuplicateMember {
+		return err
+	}
+	return fmt.Errorf("cannot JSON decode %s: %s", part, err)
+}
+
+// WithClock returns option that makes decoding use given function instead
+// of time.Now to get current time when validating token lifetime.
+func WithClock(now func() time.Time) DecodeOption {
+	return func(o *decodeOptions) {
+		o.now = now
+	}
+}
+
+// WithType returns option that makes decoding fail with ErrInvalidType
+// unless token type ("typ") header is equal to given media type. As defined
+// in RFC 7515, section 4.1.9, comparison is case insensitive and
+// "application/" prefix can be omitted.
+func WithType(typ string) DecodeOption {
+	return func(o *decodeOptions) {
+		o.typ = typ
+	}
+}
+
+// typeMatches returns true if both media types are equal.
+func typeMatches(got []byte, want string) bool {
+	const prefix = "application/"
+	if len(got) > len(prefix) && strings.EqualFold(string(got[:len(prefix)]), prefix) {
+		got = got[len(prefix):]
+	}
+	if len(want) > len(prefix) && strings.EqualFold(want[:len(prefix)], prefix) {
+		want = want[len(prefix):]
+	}
+	return strings.EqualFold(string(got), want)
+}
+
+// WithReplayStore returns option that makes decoding reject tokens that were
+// already decoded or revoked, using token ID ("jti") tracked by given store.
+// Tokens without ID are rejected with ErrMissingTokenID.
+func WithReplayStore(store ReplayStore) DecodeOption {
+	return func(o *decodeOptions) {
+		o.replay = store
+	}
+}
+
+// WithStrictEncoding returns option that enables or disables strict base64
+// decoding of token segments. In strict mode segments that are padded,
+// contain line breaks or have non-zero trailing bits are rejected with
+// SegmentError wrapping ErrNonCanonicalEncoding, so that every token has only
+// one valid encoding. Strict mode is disabled by default for DecodeClaims and
+// enabled by default for ParseUnverified.
+func WithStrictEncoding(strict bool) DecodeOption {
+	return func(o *decodeOptions) {
+		o.strict = strict
+	}
+}
+
 // DecodeHeader extract and decode header part of the JWT token into given
 // header structure. Token is not validated, therefore sigature must be
 // checked before extracted data can be trusted.
@@ -185,37 +598,69 @@ func DecodeClaims(token []byte, v Verifier, claims interface{}) error {
 // algorithm.
 func DecodeHeader(token []byte, header interface{}) error {
 	baseHeader := bytes.SplitN(token, []byte{'.'}, 2)[0]
-	baseHeader = fixPadding(baseHeader)
-	jsonHeader, err := enc.DecodeString(string(baseHeader))
+	jsonHeader, err := decodeSegment(baseHeader, false)
 	if err != nil {
 		return fmt.Errorf("invalid base64 encoding: %s", err)
 	}
-	if err := json.Unmarshal([]byte(jsonHeader), &header); err != nil {
+	if err := json.Unmarshal(jsonHeader, &header); err != nil {
 		return fmt.Errorf("invalid JSON: %s", err)
 	}
 	return nil
 }
 
-// maxlen returns length of the longest []byte element from given collection
-func maxlen(a [][]byte) int {
-	max := 0
-	for _, b := range a {
-		if l := len(b); l > max {
-			max = l
+// decodedLen returns maximum length of decoded base64 segment.
+func decodedLen(b []byte) int {
+	return enc.DecodedLen(len(b))
+}
+
+// decodeInto decodes base64 encoded segment into given buffer that must be
+// big enough. Decoded part of the buffer is returned. Unless strict, padding
+// and line breaks are ignored and trailing bits are not checked. In strict
+// mode ErrNonCanonicalEncoding is returned for such segments.
+func decodeInto(buf, b []byte, strict bool) ([]byte, error) {
+	if !strict {
+		b = bytes.TrimRight(b, "=")
+		n, err := enc.Decode(buf[:decodedLen(b)], b)
+		if err != nil {
+			return nil, err
 		}
+		return buf[:n], nil
 	}
-	return max
-}
 
-// fixPadding return given base64 encoded string with padding characters added
-// if necessary.
-func fixPadding(b []byte) []byte {
-	if n := len(b) % 4; n > 0 {
-		res := make([]byte, len(b), len(b)+4)
-		copy(res, b)
-		return append(res, bytes.Repeat([]byte("="), 4-n)...)
+	if bytes.IndexAny(b, "=\r\n") >= 0 {
+		return nil, ErrNonCanonicalEncoding
+	}
+	n, err := strictEnc.Decode(buf[:decodedLen(b)], b)
+	if err != nil {
+		// distinguish non-canonical trailing bits from invalid input
+		if _, lerr := enc.Decode(buf[:decodedLen(b)], b); lerr == nil {
+			return nil, ErrNonCanonicalEncoding
+		}
+		return nil, err
 	}
-	return b
+	return buf[:n], nil
+}
+
+// decodeSegment decodes base64 encoded segment, see decodeInto.
+func decodeSegment(b []byte, strict bool) ([]byte, error) {
+	return decodeInto(make([]byte, decodedLen(b)), b, strict)
+}
+
+// SegmentError is returned when token segment cannot be base64 decoded.
+type SegmentError struct {
+	// Segment is the name of the invalid segment: "header", "claims" or
+	// "signature".
+	Segment string
+
+	Err error
+}
+
+func (e *SegmentError) Error() string {
+	return fmt.Sprintf("cannot base64 decode %s: %s", e.Segment, e.Err)
+}
+
+func (e *SegmentError) Unwrap() error {
+	return e.Err
 }
 
 var (
@@ -230,6 +675,25 @@ var (
 	// ErrMalformedToken is returned when given token cannot be deserialized.
 	ErrMalformedToken = errors.New("malformed token")
 
+	// ErrNonCanonicalEncoding is returned in strict mode for token segments
+	// that are not encoded as required by RFC 7515, section 2: without
+	// padding, line breaks and with unused trailing bits set to zero.
+	ErrNonCanonicalEncoding = errors.New("non-canonical base64 encoding")
+
+	// ErrTokenTooLarge, ErrHeaderTooLarge and ErrClaimsTooLarge are
+	// returned when token or its part exceeds size limit.
+	ErrTokenTooLarge  = errors.New("token too large")
+	ErrHeaderTooLarge = errors.New("header too large")
+	ErrClaimsTooLarge = errors.New("claims too large")
+
+	// ErrNestingTooDeep is returned when header or claims JSON exceeds
+	// nesting depth limit.
+	ErrNestingTooDeep = errors.New("JSON nesting too deep")
+
+	// ErrDuplicateMember is returned when header or claims contain the
+	// same member name more than once.
+	ErrDuplicateMember = errors.New("duplicate JSON member name")
+
 	// ErrInvalidSigner is returned when verifying data with verifier that
 	// is using different algorithm than signer or used key ID is provided
 	// within token and does not match one returned by verifier.
@@ -241,6 +705,37 @@ var (
 	// ErrNotReady is returned when decoding token that is defining not
 	// before information and value is not yet expired.
 	ErrNotReady = errors.New("token not yet active")
+
+	// ErrNoPublicKey is returned when public key is required, but given
+	// signer or verifier does not provide one, for example because it is
+	// using symmetric algorithm.
+	ErrNoPublicKey = errors.New("no public key")
+
+	// ErrUnsupportedKey is returned when JSON Web Key is using key type or
+	// algorithm that is not supported.
+	ErrUnsupportedKey = errors.New("unsupported key")
+
+	// ErrInvalidType is returned when decoding token which type ("typ")
+	// is different than expected.
+	ErrInvalidType = errors.New("invalid token type")
+
+	// ErrMissingTokenID is returned when replay protection is enabled, but
+	// decoded token does not provide token ID ("jti").
+	ErrMissingTokenID = errors.New("missing token ID")
+
+	// ErrReplayed is returned when decoding token which ID was already
+	// seen.
+	ErrReplayed = errors.New("token replayed")
+
+	// ErrRevoked is returned when decoding token which ID was revoked.
+	ErrRevoked = errors.New("token revoked")
+
+	// ErrNoActiveKey is returned when signing using rotating signer that
+	// does not hold any key that is already active.
+	ErrNoActiveKey = errors.New("no active key")
 )
 
-var enc = base64.URLEncoding
+var (
+	enc       = base64.RawURLEncoding
+	strictEnc = enc.Strict()
+)
@@ -1,8 +1,13 @@
 package jwt
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -10,6 +15,7 @@ func TestEncode(t *testing.T) {
 	cases := map[string]struct {
 		signer    Signer
 		claims    interface{}
+		opts      []EncodeOption
 		wantToken string
 		wantErr   error
 	}{
@@ -18,10 +24,21 @@ func TestEncode(t *testing.T) {
 			claims:    map[string]string{"foo": "bar"},
 			wantToken: "eyJ0eXAiOiJKV1QiLCJhbGciOiJub25lIn0.eyJmb28iOiJiYXIifQ.",
 		},
+		"ok-extra-header": {
+			signer: noneSigner{},
+			claims: map[string]string{"foo": "bar"},
+			opts: []EncodeOption{
+				EncodeWithType("at+jwt"),
+				EncodeWithHeader("zip", "none"),
+				EncodeWithHeader("cty", "JWT"),
+			},
+			// {"typ":"at+jwt","alg":"none","cty":"JWT","zip":"none"}
+			wantToken: "eyJ0eXAiOiJhdCtqd3QiLCJhbGciOiJub25lIiwiY3R5IjoiSldUIiwiemlwIjoibm9uZSJ9.eyJmb28iOiJiYXIifQ.",
+		},
 	}
 
 	for tname, tc := range cases {
-		token, err := Encode(tc.signer, tc.claims)
+		token, err := Encode(tc.signer, tc.claims, tc.opts...)
 		if token := string(token); tc.wantToken != token {
 			t.Errorf("%s: want %q token, got %q", tname, tc.wantToken, token)
 			continue
@@ -94,6 +111,81 @@ func TestDecodeClaim(t *testing.T) {
 	}
 }
 
+func TestDecodeClaimsLifetime(t *testing.T) {
+	signer := HMAC256([]byte("top secret 7720132"), "")
+
+	cases := map[string]struct {
+		claims      string
+		pad         bool
+		wantErr     error
+		wantJSONErr bool
+	}{
+		"ok":               {claims: `{"exp": 2889062211, "nbf": 1234}`},
+		"ok-padded":        {claims: `{"exp": 2889062211, "x": "ab"}`, pad: true},
+		"expired":          {claims: `{"exp": 1234}`, wantErr: ErrExpired},
+		"expired-fraction": {claims: `{"exp": 1234.5}`, wantErr: ErrExpired},
+		"expired-escaped":  {claims: `{"e\u0078p": 1234}`, wantErr: ErrExpired},
+		"expired-case":     {claims: `{"EXP": 1234}`, wantErr: ErrExpired},
+		"expired-variant":  {claims: `{"exp": 2889062211, "eXp": 1234}`, wantErr: ErrExpired},
+		"not-ready-case":   {claims: `{"Nbf": 6478793115}`, wantErr: ErrNotReady},
+		"not-ready":        {claims: `{"nbf": 6478793115}`, wantErr: ErrNotReady},
+		"duplicate":        {claims: `{"nbf": 1234, "nbf": 6478793115}`, wantErr: ErrDuplicateMember},
+		"null-expiration":  {claims: `{"exp": null}`},
+		"huge-not-before":  {claims: `{"nbf": 1e300}`, wantJSONErr: true},
+		"long-not-before":  {claims: `{"nbf": 99999999999999999999}`, wantJSONErr: true},
+		"huge-expiration":  {claims: `{"exp": 1e19}`, wantJSONErr: true},
+		"min-expiration":   {claims: `{"exp": -1e19}`, wantJSONErr: true},
+		"exact-limit":      {claims: `{"exp": 9223372036854775808}`, wantJSONErr: true},
+	}
+
+	for tname, tc := range cases {
+		token, err := Encode(signer, json.RawMessage(tc.claims))
+		if err != nil {
+			t.Fatalf("%s: cannot encode: %s", tname, err)
+		}
+		if tc.pad {
+			parts := bytes.Split(token, []byte("."))
+			for i, p := range parts {
+				if n := len(p) % 4; n != 0 {
+					parts[i] = append(p, bytes.Repeat([]byte("="), 4-n)...)
+				}
+			}
+			token = bytes.Join(parts, []byte("."))
+		}
+
+		var claims map[string]interface{}
+		err = DecodeClaims(token, signer, &claims)
+		if tc.wantJSONErr {
+			if err == nil || !strings.HasPrefix(err.Error(), "cannot JSON decode claims") {
+				t.Errorf("%s: want JSON error, got %v", tname, err)
+			}
+			continue
+		}
+		if err != tc.wantErr {
+			t.Errorf("%s: want %v error, got %v", tname, tc.wantErr, err)
+		}
+	}
+}
+
+func TestDecodeClaimsNonPointer(t *testing.T) {
+	type claim struct {
+		Color string `json:"color"`
+	}
+	signer := HMAC256([]byte("top secret 7720132"), "")
+	token, err := Encode(signer, claim{Color: "blue"})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+
+	if err := DecodeClaims(token, signer, claim{}); err == nil || !strings.HasPrefix(err.Error(), "cannot JSON decode claims") {
+		t.Fatalf("want JSON error, got %v", err)
+	}
+	var c claim
+	if err := DecodeClaims(token, signer, &c); err != nil || c.Color != "blue" {
+		t.Fatalf("cannot decode: %v, %+v", err, c)
+	}
+}
+
 type noneSigner struct{}
 
 func (noneSigner) Algorithm() string {
@@ -160,3 +252,206 @@ func ExampleDecodeClaims() {
 	}
 	// Output: payload: {john.smith@example.com true 2889062211}
 }
+
+func BenchmarkDecodeClaims(b *testing.B) {
+	type claim struct {
+		Color          string `json:"color"`
+		Score          int    `json:"score"`
+		ExpirationTime int64  `json:"exp"`
+	}
+	payload := claim{Color: "blue", Score: 6, ExpirationTime: 2889062211}
+
+	verifiers := map[string]Signer{
+		"HS256": HMAC256([]byte("top secret 4422810"), "hmac-key"),
+		"RS256": RSA256Signer(privRSA, "rsa-key"),
+	}
+	for name, sig := range verifiers {
+		token, err := Encode(sig, payload)
+		if err != nil {
+			b.Fatalf("cannot encode: %s", err)
+		}
+
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			var c claim
+			for i := 0; i < b.N; i++ {
+				if err := DecodeClaims(token, sig, &c); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(name+"-verify-only", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := DecodeClaims(token, sig, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeClaimsStrictEncoding(t *testing.T) {
+	signer := HMAC256([]byte("top secret 7720132"), "")
+	token, err := Encode(signer, map[string]interface{}{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+	dot := bytes.LastIndexByte(token, '.')
+
+	// HS256 signature is 32 bytes long, so last character carries two
+	// unused bits
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	nonCanonical := append([]byte{}, token...)
+	last := len(nonCanonical) - 1
+	nonCanonical[last] = alphabet[strings.IndexByte(alphabet, nonCanonical[last])^1]
+
+	withLineBreak := append([]byte{}, token[:dot+5]...)
+	withLineBreak = append(withLineBreak, '\n')
+	withLineBreak = append(withLineBreak, token[dot+5:]...)
+
+	cases := map[string]struct {
+		token       []byte
+		wantSegment string
+	}{
+		"canonical":      {token: token},
+		"padded":         {token: append(append([]byte{}, token...), '='), wantSegment: "signature"},
+		"line-break":     {token: withLineBreak, wantSegment: "signature"},
+		"trailing-bits":  {token: nonCanonical, wantSegment: "signature"},
+		"padded-header":  {token: bytes.Replace(token, []byte("."), []byte("=."), 1), wantSegment: "header"},
+		"invalid-claims": {token: bytes.Replace(token, []byte("."), []byte(".!"), 1), wantSegment: "claims"},
+	}
+
+	for tname, tc := range cases {
+		err := DecodeClaims(tc.token, signer, nil, WithStrictEncoding(true))
+		_, perr := ParseUnverified(tc.token)
+		if tc.wantSegment == "" {
+			if err != nil || perr != nil {
+				t.Errorf("%s: want no error, got %v and %v", tname, err, perr)
+			}
+			continue
+		}
+		for _, err := range []error{err, perr} {
+			var serr *SegmentError
+			if !errors.As(err, &serr) || serr.Segment != tc.wantSegment {
+				t.Errorf("%s: want %s segment error, got %v", tname, tc.wantSegment, err)
+			}
+		}
+		if tname != "invalid-claims" && !errors.Is(err, ErrNonCanonicalEncoding) {
+			t.Errorf("%s: want ErrNonCanonicalEncoding, got %v", tname, err)
+		}
+	}
+
+	// non-canonical encodings are accepted unless strict mode is enabled
+	if err := DecodeClaims(nonCanonical, signer, nil); err != nil {
+		t.Fatalf("lenient decoding: %s", err)
+	}
+	if _, err := ParseUnverified(nonCanonical, WithStrictEncoding(false)); err != nil {
+		t.Fatalf("lenient parsing: %s", err)
+	}
+}
+
+func TestDecodeClaimsLimits(t *testing.T) {
+	signer := HMAC256([]byte("top secret 7720132"), "")
+	encode := func(claims string, opts ...EncodeOption) []byte {
+		token, err := Encode(signer, json.RawMessage(claims), opts...)
+		if err != nil {
+			t.Fatalf("cannot encode: %s", err)
+		}
+		return token
+	}
+	large := `{"data": "` + strings.Repeat("x", 40<<10) + `"}`
+	deep := `{"a": ` + strings.Repeat("[", 40) + strings.Repeat("]", 40) + `}`
+
+	cases := map[string]struct {
+		token   []byte
+		limits  *Limits
+		wantErr error
+	}{
+		"default-ok":        {token: encode(`{"a": [[{"b": 1}]]}`)},
+		"token-too-large":   {token: encode(large), limits: &Limits{MaxTokenSize: 1 << 10}, wantErr: ErrTokenTooLarge},
+		"claims-too-large":  {token: encode(large), wantErr: ErrClaimsTooLarge},
+		"claims-no-limit":   {token: encode(large), limits: &Limits{}},
+		"header-too-large":  {token: encode(`{}`, EncodeWithHeader("x5c", strings.Repeat("x", 100))), limits: &Limits{MaxHeaderSize: 64}, wantErr: ErrHeaderTooLarge},
+		"nesting-too-deep":  {token: encode(deep), wantErr: ErrNestingTooDeep},
+		"duplicate-claim":   {token: encode(`{"nbf": 1234, "nbf": 6478793115}`), wantErr: ErrDuplicateMember},
+		"duplicate-allowed": {token: encode(`{"nbf": 1234, "nbf": 6478793115}`), limits: &Limits{AllowDuplicateMembers: true}, wantErr: ErrNotReady},
+		"duplicate-header":  {token: append([]byte("eyJhbGciOiJIUzI1NiIsImFsZyI6IkhTMjU2In0"), encode(`{}`)[bytes.IndexByte(encode(`{}`), '.'):]...), wantErr: ErrDuplicateMember},
+	}
+
+	for tname, tc := range cases {
+		var opts []DecodeOption
+		if tc.limits != nil {
+			opts = append(opts, WithLimits(*tc.limits))
+		}
+		if err := DecodeClaims(tc.token, signer, nil, opts...); err != tc.wantErr {
+			t.Errorf("%s: want %v, got %v", tname, tc.wantErr, err)
+		}
+		if tc.wantErr == ErrNotReady {
+			continue
+		}
+		if _, err := ParseUnverified(tc.token, opts...); err != tc.wantErr {
+			t.Errorf("%s: parse: want %v, got %v", tname, tc.wantErr, err)
+		}
+	}
+}
+
+// contextSigner is ContextSigner that fails when context carries no value
+// for ctxKey.
+type contextSigner struct {
+	Signer
+}
+
+type ctxKey struct{}
+
+func (s contextSigner) SignContext(ctx context.Context, data []byte) ([]byte, error) {
+	if ctx.Value(ctxKey{}) == nil {
+		return nil, errors.New("context not passed")
+	}
+	return s.Signer.Sign(data)
+}
+
+func (s contextSigner) VerifyContext(ctx context.Context, signature, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if ctx.Value(ctxKey{}) == nil {
+		return errors.New("context not passed")
+	}
+	return s.Signer.Verify(signature, data)
+}
+
+func TestEncodeDecodeContext(t *testing.T) {
+	sig := contextSigner{HMAC256([]byte("top secret 7720132"), "")}
+	ctx := context.WithValue(context.Background(), ctxKey{}, true)
+
+	if _, err := Encode(sig, map[string]string{"sub": "alice"}); err == nil {
+		t.Fatal("want error when signing without context")
+	}
+	token, err := EncodeContext(ctx, sig, map[string]string{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+
+	if err := DecodeClaims(token, sig, nil); err == nil {
+		t.Fatal("want error when verifying without context")
+	}
+	var claims map[string]string
+	if err := DecodeClaimsContext(ctx, token, sig, &claims); err != nil {
+		t.Fatalf("cannot decode: %s", err)
+	}
+	if claims["sub"] != "alice" {
+		t.Fatalf("unexpected claims: %v", claims)
+	}
+
+	// plain verifier is used as before
+	if err := DecodeClaimsContext(ctx, token, sig.Signer, nil); err != nil {
+		t.Fatalf("cannot decode using plain verifier: %s", err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := DecodeClaimsContext(canceled, token, sig, nil); err != context.Canceled {
+		t.Fatalf("want context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+// Package jwthooks provides jwt.Hooks implementations exposing decoding
+// outcomes as expvar metrics and log/slog records.
+//
+// It is a separate package, because importing expvar registers its HTTP
+// handler on http.DefaultServeMux.
+package jwthooks
+
+import (
+	"context"
+	"expvar"
+	"strconv"
+	"time"
+
+	"github.com/opinary/jwt"
+)
+
+// DurationBuckets are upper bounds of the decoding duration histogram
+// exposed by Expvar.
+var DurationBuckets = []time.Duration{
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+}
+
+// Expvar is jwt.Hooks publishing decoding counters as expvar map. The map
+// contains:
+//
+//	decoded                total number of decoded tokens
+//	outcome.<category>     number of tokens per jwt.ErrorCategory
+//	algorithm.<alg>        number of successfully decoded tokens per algorithm
+//	duration_le_<us>       cumulative duration histogram, in microseconds
+//	duration_le_inf        with upper bounds taken from DurationBuckets
+//
+// Algorithm is counted only for valid tokens, so that forged tokens cannot
+// create arbitrary number of map keys.
+type Expvar struct {
+	m       *expvar.Map
+	buckets []time.Duration
+	names   []string
+}
+
+var _ jwt.Hooks = (*Expvar)(nil)
+
+// NewExpvar returns hooks publishing expvar map with given name. Like
+// expvar.NewMap, it panics if the name is already registered.
+func NewExpvar(name string) *Expvar {
+	e := &Expvar{
+		m:       expvar.NewMap(name),
+		buckets: append([]time.Duration{}, DurationBuckets...),
+	}
+	for _, b := range e.buckets {
+		e.names = append(e.names, "duration_le_"+strconv.FormatInt(b.Microseconds(), 10))
+	}
+	return e
+}
+
+// Map returns published expvar map.
+func (e *Expvar) Map() *expvar.Map {
+	return e.m
+}
+
+// TokenDecoded updates counters with the decoding outcome.
+func (e *Expvar) TokenDecoded(ctx context.Context, event jwt.DecodeEvent) {
+	e.m.Add("decoded", 1)
+	e.m.Add("outcome."+event.Category, 1)
+	if event.Err == nil {
+		e.m.Add("algorithm."+event.Algorithm, 1)
+	}
+	for i, b := range e.buckets {
+		if event.Duration <= b {
+			e.m.Add(e.names[i], 1)
+		}
+	}
+	e.m.Add("duration_le_inf", 1)
+}
@@ -0,0 +1,44 @@
+package jwthooks
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/opinary/jwt"
+)
+
+// expvarRuns makes published map names unique when tests are repeated, since
+// expvar names cannot be registered twice.
+var expvarRuns int
+
+func TestExpvar(t *testing.T) {
+	expvarRuns++
+	hooks := NewExpvar("jwthooks_test_" + strconv.Itoa(expvarRuns))
+	signer := jwt.HMAC256([]byte("secret"), "")
+	token, err := jwt.Encode(signer, map[string]interface{}{"exp": time.Now().Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+
+	for _, token := range [][]byte{token, token, []byte("invalid")} {
+		_ = jwt.DecodeClaims(token, signer, nil, jwt.WithHooks(hooks))
+	}
+
+	want := map[string]string{
+		"decoded":           "3",
+		"outcome.ok":        "2",
+		"outcome.malformed": "1",
+		"algorithm.HS256":   "2",
+		"duration_le_inf":   "3",
+	}
+	for name, value := range want {
+		v := hooks.Map().Get(name)
+		if v == nil || v.String() != value {
+			t.Errorf("%s: want %s, got %v", name, value, v)
+		}
+	}
+	if hooks.Map().Get("algorithm.") != nil {
+		t.Error("algorithm of invalid token counted")
+	}
+}
@@ -0,0 +1,68 @@
+package jwthooks
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/opinary/jwt"
+)
+
+// Slog is jwt.Hooks writing every decoding outcome to slog logger. Token
+// claims and signature are never logged. Note that for failed decoding,
+// logged algorithm, key ID and issuer are controlled by the token author.
+type Slog struct {
+	// Logger is used to write records. slog.Default is used if nil.
+	Logger *slog.Logger
+
+	// SuccessLevel and FailureLevel are levels of records written for
+	// successful and failed decoding. If nil, successful decoding is
+	// logged at debug level and failed one at info level.
+	SuccessLevel slog.Leveler
+	FailureLevel slog.Leveler
+}
+
+var _ jwt.Hooks = (*Slog)(nil)
+
+// NewSlog returns hooks using given logger with default levels.
+func NewSlog(logger *slog.Logger) *Slog {
+	return &Slog{Logger: logger}
+}
+
+// TokenDecoded writes record describing decoding outcome.
+func (s *Slog) TokenDecoded(ctx context.Context, event jwt.DecodeEvent) {
+	logger := s.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	level, msg := levelOf(s.SuccessLevel, slog.LevelDebug), "token verified"
+	if event.Err != nil {
+		level, msg = levelOf(s.FailureLevel, slog.LevelInfo), "token rejected"
+	}
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("outcome", event.Category),
+		slog.String("alg", event.Algorithm),
+		slog.Duration("duration", event.Duration),
+	}
+	if event.KeyID != "" {
+		attrs = append(attrs, slog.String("kid", event.KeyID))
+	}
+	if event.Issuer != "" {
+		attrs = append(attrs, slog.String("iss", event.Issuer))
+	}
+	if event.Err != nil {
+		attrs = append(attrs, slog.String("error", event.Err.Error()))
+	}
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// levelOf returns level of given leveler, or default level if it is nil.
+func levelOf(l slog.Leveler, def slog.Level) slog.Level {
+	if l == nil {
+		return def
+	}
+	return l.Level()
+}
@@ -0,0 +1,76 @@
+package jwthooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/opinary/jwt"
+)
+
+func TestSlog(t *testing.T) {
+	var buf bytes.Buffer
+	hooks := NewSlog(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	signer := jwt.HMAC256([]byte("secret"), "hmac-key")
+	token, err := jwt.Encode(signer, map[string]interface{}{"iss": "issuer", "secret_claim": "classified", "exp": 1})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+	if err := jwt.DecodeClaims(token, signer, nil, jwt.WithHooks(hooks)); err != jwt.ErrExpired {
+		t.Fatalf("want ErrExpired, got %v", err)
+	}
+
+	if strings.Contains(buf.String(), "classified") || strings.Contains(buf.String(), string(token)) {
+		t.Fatalf("token content logged: %s", buf.String())
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("cannot decode record: %s", err)
+	}
+	want := map[string]interface{}{
+		"level":   "INFO",
+		"msg":     "token rejected",
+		"outcome": "expired",
+		"alg":     "HS256",
+		"kid":     "hmac-key",
+		"iss":     "issuer",
+		"error":   jwt.ErrExpired.Error(),
+	}
+	for name, value := range want {
+		if record[name] != value {
+			t.Errorf("%s: want %v, got %v", name, value, record[name])
+		}
+	}
+
+	// successful decoding is not logged at info level, also by zero value
+	// hooks
+	buf.Reset()
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	token, err = jwt.Encode(signer, map[string]interface{}{"iss": "issuer"})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+	for _, hooks := range []*Slog{NewSlog(logger), {Logger: logger}} {
+		if err := jwt.DecodeClaims(token, signer, nil, jwt.WithHooks(hooks)); err != nil {
+			t.Fatalf("cannot decode: %s", err)
+		}
+		if buf.Len() != 0 {
+			t.Fatalf("unexpected record: %s", buf.String())
+		}
+	}
+
+	hooks = &Slog{Logger: logger, SuccessLevel: slog.LevelWarn}
+	if err := jwt.DecodeClaims(token, signer, nil, jwt.WithHooks(hooks)); err != nil {
+		t.Fatalf("cannot decode: %s", err)
+	}
+	record = nil
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("cannot decode record: %s", err)
+	}
+	if record["level"] != "WARN" || record["msg"] != "token verified" {
+		t.Fatalf("unexpected record: %s", buf.String())
+	}
+}
@@ -0,0 +1,114 @@
+// Package jwtotel provides OpenTelemetry tracing of token encoding, decoding,
+// signing, verification and key lookup.
+//
+// Spans carry algorithm ("jwt.alg") and key ID ("jwt.kid") attributes. Failed
+// operations set span status to error and record error kind, as returned by
+// jwt.ErrorCategory, in "jwt.error.type" attribute. Token claims and
+// signature are never recorded. Note that for failed decoding, recorded
+// algorithm and key ID are controlled by the token author.
+//
+// Context given to Encode and DecodeClaims is passed down to signers, key
+// sets and verifiers implementing context aware interfaces, so that spans
+// created by them, for example by instrumented HTTP client fetching remote
+// keys, are children of the jwt span.
+//
+// This package is a separate module, so that only its users depend on
+// OpenTelemetry.
+package jwtotel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/opinary/jwt"
+)
+
+// ScopeName is the instrumentation scope name of the tracer.
+const ScopeName = "github.com/opinary/jwt/jwtotel"
+
+// Span attribute keys.
+const (
+	AlgorithmKey = attribute.Key("jwt.alg")
+	KeyIDKey     = attribute.Key("jwt.kid")
+	ErrorTypeKey = attribute.Key("jwt.error.type")
+)
+
+// Tracer creates spans for jwt operations. It is safe for concurrent use.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// New returns tracer using given provider. Global provider is used if nil.
+func New(provider trace.TracerProvider) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return &Tracer{tracer: provider.Tracer(ScopeName)}
+}
+
+// Encode is like jwt.EncodeContext, but runs within "jwt.encode" span.
+func (t *Tracer) Encode(ctx context.Context, sig jwt.Signer, claims interface{}, opts ...jwt.EncodeOption) ([]byte, error) {
+	ctx, span := t.start(ctx, "jwt.encode", sig.Algorithm(), keyID(sig))
+	defer span.End()
+
+	token, err := jwt.EncodeContext(ctx, sig, claims, opts...)
+	finish(span, err)
+	return token, err
+}
+
+// DecodeClaims is like jwt.DecodeClaimsContext, but runs within "jwt.decode"
+// span. Algorithm and key ID recorded are these declared by the token
+// header.
+func (t *Tracer) DecodeClaims(ctx context.Context, token []byte, v jwt.Verifier, claims interface{}, opts ...jwt.DecodeOption) error {
+	ctx, span := t.tracer.Start(ctx, "jwt.decode", trace.WithSpanKind(trace.SpanKindInternal))
+	defer span.End()
+
+	opts = append(opts[:len(opts):len(opts)], jwt.WithHooks(spanHooks{}))
+	return jwt.DecodeClaimsContext(ctx, token, v, claims, opts...)
+}
+
+// spanHooks annotates span found in context with decoding outcome.
+type spanHooks struct{}
+
+func (spanHooks) TokenDecoded(ctx context.Context, event jwt.DecodeEvent) {
+	span := trace.SpanFromContext(ctx)
+	if event.Algorithm != "" {
+		span.SetAttributes(AlgorithmKey.String(event.Algorithm))
+	}
+	if event.KeyID != "" {
+		span.SetAttributes(KeyIDKey.String(event.KeyID))
+	}
+	finish(span, event.Err)
+}
+
+func (t *Tracer) start(ctx context.Context, name, alg, kid string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{AlgorithmKey.String(alg)}
+	if kid != "" {
+		attrs = append(attrs, KeyIDKey.String(kid))
+	}
+	return t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrs...))
+}
+
+// finish records error, if any, on the span.
+func finish(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	category := jwt.ErrorCategory(err)
+	span.SetAttributes(ErrorTypeKey.String(category))
+	span.RecordError(err)
+	span.SetStatus(codes.Error, category)
+}
+
+func keyID(v jwt.Verifier) string {
+	if v, ok := v.(interface{ KeyID() string }); ok {
+		return v.KeyID()
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+package jwtotel
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/opinary/jwt"
+	"github.com/opinary/jwt/jwttest"
+)
+
+func newTracer(t *testing.T) (*Tracer, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+	return New(provider), exporter
+}
+
+func attr(span tracetest.SpanStub, key attribute.Key) string {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value.AsString()
+		}
+	}
+	return ""
+}
+
+func TestEncodeDecode(t *testing.T) {
+	tracer, exporter := newTracer(t)
+
+	token, err := tracer.Encode(context.Background(), jwttest.Signer(jwttest.RS256), map[string]string{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+
+	cases := map[string]struct {
+		token     []byte
+		wantType  string
+		wantError bool
+	}{
+		"valid": {
+			token: token,
+		},
+		"expired": {
+			token:     jwttest.New(jwttest.RS256).Expired().MustBuild(t),
+			wantType:  "expired",
+			wantError: true,
+		},
+		"tampered": {
+			token:     jwttest.New(jwttest.RS256).Tampered().MustBuild(t),
+			wantType:  "signature",
+			wantError: true,
+		},
+		"wrong-kid": {
+			token:     jwttest.New(jwttest.RS256).WrongKeyID().MustBuild(t),
+			wantType:  "signer",
+			wantError: true,
+		},
+	}
+
+	for tname, tc := range cases {
+		exporter.Reset()
+		err := tracer.DecodeClaims(context.Background(), tc.token, jwttest.Verifier(jwttest.RS256), nil)
+		if (err != nil) != tc.wantError {
+			t.Errorf("%s: unexpected error: %v", tname, err)
+		}
+
+		spans := exporter.GetSpans()
+		if len(spans) != 1 || spans[0].Name != "jwt.decode" {
+			t.Errorf("%s: want one jwt.decode span, got %d", tname, len(spans))
+			continue
+		}
+		span := spans[0]
+		if alg := attr(span, AlgorithmKey); alg != jwttest.RS256 {
+			t.Errorf("%s: want %s algorithm, got %q", tname, jwttest.RS256, alg)
+		}
+		if kid := attr(span, KeyIDKey); kid == "" {
+			t.Errorf("%s: want key ID attribute", tname)
+		}
+		if got := attr(span, ErrorTypeKey); got != tc.wantType {
+			t.Errorf("%s: want %q error type, got %q", tname, tc.wantType, got)
+		}
+		if tc.wantError && span.Status.Code != codes.Error {
+			t.Errorf("%s: want error status, got %v", tname, span.Status)
+		}
+		if !tc.wantError && span.Status.Code == codes.Error {
+			t.Errorf("%s: unexpected error status: %v", tname, span.Status)
+		}
+	}
+}
+
+func TestEncodeSpan(t *testing.T) {
+	tracer, exporter := newTracer(t)
+
+	sig := tracer.Signer(jwttest.Signer(jwttest.HS256))
+	token, err := tracer.Encode(context.Background(), sig, map[string]string{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+	if err := jwt.DecodeClaims(token, jwttest.Verifier(jwttest.HS256), nil); err != nil {
+		t.Fatalf("cannot decode: %s", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("want two spans, got %d", len(spans))
+	}
+	// spans are exported when they end, so the child comes first
+	sign, encode := spans[0], spans[1]
+	if sign.Name != "jwt.sign" || encode.Name != "jwt.encode" {
+		t.Fatalf("unexpected spans: %s, %s", sign.Name, encode.Name)
+	}
+	if sign.Parent.SpanID() != encode.SpanContext.SpanID() {
+		t.Error("sign span is not a child of encode span")
+	}
+	for _, span := range spans {
+		if alg := attr(span, AlgorithmKey); alg != jwttest.HS256 {
+			t.Errorf("%s: want %s algorithm, got %q", span.Name, jwttest.HS256, alg)
+		}
+		if kid := attr(span, KeyIDKey); kid != jwttest.KeyID {
+			t.Errorf("%s: want %s key ID, got %q", span.Name, jwttest.KeyID, kid)
+		}
+	}
+}
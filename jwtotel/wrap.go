@@ -0,0 +1,155 @@
+package jwtotel
+
+import (
+	"context"
+
+	"github.com/opinary/jwt"
+)
+
+// Signer returns signer creating "jwt.sign" span for every signature and
+// "jwt.verify" span for every verification. Returned signer preserves key
+// ID and signing key rotation of the wrapped one, but no other optional
+// methods, so it should be used for signing only and not, for example, to
+// publish public key.
+func (t *Tracer) Signer(sig jwt.Signer) jwt.Signer {
+	s := &signer{verifier: verifier{t: t, v: sig}, sig: sig}
+	switch sig.(type) {
+	case interface{ Current() (jwt.Signer, error) }:
+		return &rotatingSigner{signer: s}
+	case interface{ KeyID() string }:
+		return &keyedSigner{signer: s}
+	}
+	return s
+}
+
+// Verifier returns verifier creating "jwt.verify" span for every
+// verification. If v is jwt.KeySet, returned verifier is jwt.ContextKeySet
+// creating "jwt.key_lookup" span for every lookup, so that remote key
+// fetches are traced within it. Verifiers returned by lookup are wrapped as
+// well. Key ID of the wrapped verifier is preserved.
+func (t *Tracer) Verifier(v jwt.Verifier) jwt.Verifier {
+	switch v.(type) {
+	case jwt.KeySet:
+		return &keySet{verifier: verifier{t: t, v: v}}
+	case interface{ KeyID() string }:
+		return &keyedVerifier{verifier: verifier{t: t, v: v}}
+	}
+	return &verifier{t: t, v: v}
+}
+
+type verifier struct {
+	t *Tracer
+	v jwt.Verifier
+}
+
+var _ jwt.ContextVerifier = (*verifier)(nil)
+
+func (v *verifier) Algorithm() string {
+	return v.v.Algorithm()
+}
+
+func (v *verifier) Verify(signature, data []byte) error {
+	return v.VerifyContext(context.Background(), signature, data)
+}
+
+func (v *verifier) VerifyContext(ctx context.Context, signature, data []byte) error {
+	ctx, span := v.t.start(ctx, "jwt.verify", v.v.Algorithm(), keyID(v.v))
+	defer span.End()
+
+	var err error
+	if cv, ok := v.v.(jwt.ContextVerifier); ok {
+		err = cv.VerifyContext(ctx, signature, data)
+	} else {
+		err = v.v.Verify(signature, data)
+	}
+	finish(span, err)
+	return err
+}
+
+type keyedVerifier struct {
+	verifier
+}
+
+func (v *keyedVerifier) KeyID() string {
+	return keyID(v.v)
+}
+
+type keySet struct {
+	verifier
+}
+
+var _ jwt.ContextKeySet = (*keySet)(nil)
+
+func (s *keySet) Lookup(alg, keyID string) (jwt.Verifier, error) {
+	return s.LookupContext(context.Background(), alg, keyID)
+}
+
+func (s *keySet) LookupContext(ctx context.Context, alg, kid string) (jwt.Verifier, error) {
+	ctx, span := s.t.start(ctx, "jwt.key_lookup", alg, kid)
+	defer span.End()
+
+	var (
+		v   jwt.Verifier
+		err error
+	)
+	if ks, ok := s.v.(jwt.ContextKeySet); ok {
+		v, err = ks.LookupContext(ctx, alg, kid)
+	} else {
+		v, err = s.v.(jwt.KeySet).Lookup(alg, kid)
+	}
+	finish(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return s.t.Verifier(v), nil
+}
+
+type signer struct {
+	verifier
+	sig jwt.Signer
+}
+
+var _ jwt.ContextSigner = (*signer)(nil)
+
+func (s *signer) Sign(data []byte) ([]byte, error) {
+	return s.SignContext(context.Background(), data)
+}
+
+func (s *signer) SignContext(ctx context.Context, data []byte) ([]byte, error) {
+	ctx, span := s.t.start(ctx, "jwt.sign", s.sig.Algorithm(), keyID(s.sig))
+	defer span.End()
+
+	var (
+		signature []byte
+		err       error
+	)
+	if cs, ok := s.sig.(jwt.ContextSigner); ok {
+		signature, err = cs.SignContext(ctx, data)
+	} else {
+		signature, err = s.sig.Sign(data)
+	}
+	finish(span, err)
+	return signature, err
+}
+
+type keyedSigner struct {
+	*signer
+}
+
+func (s *keyedSigner) KeyID() string {
+	return keyID(s.sig)
+}
+
+// rotatingSigner wraps signer selected by rotation, so that header of
+// encoded token describes the key actually used.
+type rotatingSigner struct {
+	*signer
+}
+
+func (s *rotatingSigner) Current() (jwt.Signer, error) {
+	current, err := s.sig.(interface{ Current() (jwt.Signer, error) }).Current()
+	if err != nil {
+		return nil, err
+	}
+	return s.t.Signer(current), nil
+}
@@ -0,0 +1,129 @@
+package jwtotel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/opinary/jwt"
+	"github.com/opinary/jwt/jwttest"
+)
+
+// tracingTransport starts span for every request using request context,
+// like instrumented HTTP clients do.
+type tracingTransport struct {
+	tracer trace.Tracer
+}
+
+func (t *tracingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx, span := t.tracer.Start(r.Context(), "http.get")
+	defer span.End()
+	return http.DefaultTransport.RoundTrip(r.WithContext(ctx))
+}
+
+func TestRemoteKeySetPropagation(t *testing.T) {
+	rot := jwt.NewRotatingSigner(time.Hour, nil)
+	if err := rot.Add(jwttest.Signer(jwttest.RS256), time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("cannot add key: %s", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(rot.JWKS())
+	}))
+	defer srv.Close()
+
+	tracer, exporter := newTracer(t)
+	client := &http.Client{Transport: &tracingTransport{tracer: tracer.tracer}}
+	keys := tracer.Verifier(jwt.NewRemoteKeySet(srv.URL, client))
+	if _, ok := keys.(jwt.ContextKeySet); !ok {
+		t.Fatal("key set wrapper must implement jwt.ContextKeySet")
+	}
+
+	token := jwttest.New(jwttest.RS256).MustBuild(t)
+	if err := tracer.DecodeClaims(context.Background(), token, keys, nil); err != nil {
+		t.Fatalf("cannot decode: %s", err)
+	}
+
+	spans := make(map[string]int)
+	got := exporter.GetSpans()
+	for i, span := range got {
+		spans[span.Name] = i
+	}
+	for _, name := range []string{"jwt.decode", "jwt.key_lookup", "http.get", "jwt.verify"} {
+		if _, ok := spans[name]; !ok {
+			t.Fatalf("missing %s span, got %d spans", name, len(got))
+		}
+	}
+	parents := map[string]string{
+		"http.get":       "jwt.key_lookup",
+		"jwt.key_lookup": "jwt.decode",
+		"jwt.verify":     "jwt.decode",
+	}
+	for child, parent := range parents {
+		if got[spans[child]].Parent.SpanID() != got[spans[parent]].SpanContext.SpanID() {
+			t.Errorf("%s span is not a child of %s span", child, parent)
+		}
+	}
+	if kid := attr(got[spans["jwt.key_lookup"]], KeyIDKey); kid != jwttest.KeyID {
+		t.Errorf("want %s key ID of lookup, got %q", jwttest.KeyID, kid)
+	}
+}
+
+func TestLookupError(t *testing.T) {
+	tracer, exporter := newTracer(t)
+	keys := tracer.Verifier(&jwt.JWKSet{})
+
+	token := jwttest.New(jwttest.HS256).MustBuild(t)
+	if err := jwt.DecodeClaims(token, keys, nil); err != jwt.ErrInvalidSigner {
+		t.Fatalf("want %v, got %v", jwt.ErrInvalidSigner, err)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "jwt.key_lookup" {
+		t.Fatalf("want one jwt.key_lookup span, got %d", len(spans))
+	}
+	if got := attr(spans[0], ErrorTypeKey); got != "signer" {
+		t.Errorf("want signer error type, got %q", got)
+	}
+}
+
+func TestWrappedKeyID(t *testing.T) {
+	tracer, _ := newTracer(t)
+
+	cases := map[string]struct {
+		verifier  jwt.Verifier
+		wantKeyID bool
+	}{
+		"hmac-signer":  {verifier: tracer.Signer(jwttest.Signer(jwttest.HS256)), wantKeyID: true},
+		"rsa-verifier": {verifier: tracer.Verifier(jwttest.Verifier(jwttest.RS256)), wantKeyID: true},
+		"empty-key-id": {verifier: tracer.Verifier(jwt.HMAC256(jwttest.HMACKey, "")), wantKeyID: true},
+		"plain":        {verifier: tracer.Verifier(jwt.RSA256Verifier(&jwttest.RSAKey.PublicKey))},
+	}
+
+	for tname, tc := range cases {
+		_, ok := tc.verifier.(interface{ KeyID() string })
+		if ok != tc.wantKeyID {
+			t.Errorf("%s: want key ID %t, got %t", tname, tc.wantKeyID, ok)
+		}
+	}
+
+	// token key ID must match rotated signer key
+	rot := jwt.NewRotatingSigner(time.Hour, nil)
+	if err := rot.Add(jwt.HMAC256(jwttest.HMACKey, "first"), time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("cannot add key: %s", err)
+	}
+	token, err := jwt.Encode(tracer.Signer(rot), map[string]string{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+	parsed, err := jwt.ParseUnverified(token)
+	if err != nil {
+		t.Fatalf("cannot parse: %s", err)
+	}
+	if kid := parsed.Header["kid"]; kid != "first" {
+		t.Errorf("want first key ID, got %v", kid)
+	}
+}
@@ -0,0 +1,179 @@
+package jwttest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/opinary/jwt"
+)
+
+// Factory creates tokens for tests. By default token is valid for one hour
+// and signed using Signer of the factory algorithm. Methods modify the
+// factory and return it, so that calls can be chained:
+//
+//	token := jwttest.New(jwttest.HS256).
+//		Claim("sub", "alice").
+//		Expired().
+//		MustBuild(t)
+type Factory struct {
+	alg      string
+	keyID    string
+	typ      string
+	claims   map[string]interface{}
+	now      func() time.Time
+	exp      time.Duration
+	nbf      time.Duration
+	wrongKey bool
+	tampered bool
+}
+
+// New returns factory of tokens signed with given algorithm.
+func New(alg string) *Factory {
+	return &Factory{
+		alg:    alg,
+		keyID:  KeyID,
+		claims: make(map[string]interface{}),
+		now:    time.Now,
+		exp:    time.Hour,
+	}
+}
+
+// Claim sets claim value. Claims set explicitly take precedence over "iat",
+// "exp" and "nbf" computed by the factory.
+func (f *Factory) Claim(name string, value interface{}) *Factory {
+	f.claims[name] = value
+	return f
+}
+
+// Clock makes factory use given clock for computing token lifetime.
+func (f *Factory) Clock(c *Clock) *Factory {
+	f.now = c.Now
+	return f
+}
+
+// ExpiresIn sets token expiration time relative to the current time.
+func (f *Factory) ExpiresIn(d time.Duration) *Factory {
+	f.exp = d
+	return f
+}
+
+// Expired makes token expired one minute ago.
+func (f *Factory) Expired() *Factory {
+	return f.ExpiresIn(-time.Minute)
+}
+
+// NotYetValid makes token valid only one hour from now.
+func (f *Factory) NotYetValid() *Factory {
+	f.nbf = time.Hour
+	if f.exp <= f.nbf {
+		f.exp = f.nbf + time.Hour
+	}
+	return f
+}
+
+// Type sets token type ("typ") header.
+func (f *Factory) Type(typ string) *Factory {
+	f.typ = typ
+	return f
+}
+
+// KeyID sets token key ID ("kid") header.
+func (f *Factory) KeyID(keyID string) *Factory {
+	f.keyID = keyID
+	return f
+}
+
+// WrongKeyID makes token key ID different than the one of Signer and
+// Verifier, so that decoding fails with jwt.ErrInvalidSigner.
+func (f *Factory) WrongKeyID() *Factory {
+	return f.KeyID("wrong-" + KeyID)
+}
+
+// WrongKey makes token signed with different key of the same algorithm and
+// key ID, so that decoding fails with jwt.ErrInvalidSignature.
+func (f *Factory) WrongKey() *Factory {
+	f.wrongKey = true
+	return f
+}
+
+// Tampered makes token claims modified after signing, so that decoding fails
+// with jwt.ErrInvalidSignature.
+func (f *Factory) Tampered() *Factory {
+	f.tampered = true
+	return f
+}
+
+// Build returns token created according to the factory configuration.
+func (f *Factory) Build() ([]byte, error) {
+	now := f.now()
+	claims := map[string]interface{}{
+		"iat": now.Unix(),
+		"exp": now.Add(f.exp).Unix(),
+	}
+	if f.nbf != 0 {
+		claims["nbf"] = now.Add(f.nbf).Unix()
+	}
+	for name, value := range f.claims {
+		claims[name] = value
+	}
+
+	sig, err := f.signer()
+	if err != nil {
+		return nil, err
+	}
+	var opts []jwt.EncodeOption
+	if f.typ != "" {
+		opts = append(opts, jwt.EncodeWithType(f.typ))
+	}
+	token, err := jwt.Encode(sig, claims, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if f.tampered {
+		token, err = tamper(token)
+	}
+	return token, err
+}
+
+// MustBuild is like Build, but fails the test on error.
+func (f *Factory) MustBuild(t testing.TB) []byte {
+	t.Helper()
+	token, err := f.Build()
+	if err != nil {
+		t.Fatalf("jwttest: cannot build token: %s", err)
+	}
+	return token
+}
+
+func (f *Factory) signer() (jwt.Signer, error) {
+	if f.wrongKey {
+		return signer(f.alg, otherHMACKey, otherRSAKey, f.keyID)
+	}
+	return signer(f.alg, HMACKey, RSAKey, f.keyID)
+}
+
+// tamper returns token with "tampered" claim added, keeping the original
+// signature.
+func tamper(token []byte) ([]byte, error) {
+	parts := bytes.Split(token, []byte{'.'})
+	if len(parts) != 3 {
+		return nil, jwt.ErrMalformedToken
+	}
+	b, err := base64.RawURLEncoding.DecodeString(string(parts[1]))
+	if err != nil {
+		return nil, err
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(b, &claims); err != nil {
+		return nil, err
+	}
+	claims["tampered"] = true
+	if b, err = json.Marshal(claims); err != nil {
+		return nil, err
+	}
+	parts[1] = []byte(base64.RawURLEncoding.EncodeToString(b))
+	return bytes.Join(parts, []byte{'.'}), nil
+}
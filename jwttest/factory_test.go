@@ -0,0 +1,106 @@
+package jwttest
+
+import (
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/opinary/jwt"
+)
+
+func TestFactory(t *testing.T) {
+	clock := NewClock(time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	cases := map[string]struct {
+		build   func(*Factory) *Factory
+		wantErr error
+	}{
+		"valid": {
+			build: func(f *Factory) *Factory { return f },
+		},
+		"expired": {
+			build:   (*Factory).Expired,
+			wantErr: jwt.ErrExpired,
+		},
+		"not-yet-valid": {
+			build:   (*Factory).NotYetValid,
+			wantErr: jwt.ErrNotReady,
+		},
+		"wrong-key-id": {
+			build:   (*Factory).WrongKeyID,
+			wantErr: jwt.ErrInvalidSigner,
+		},
+		"wrong-key": {
+			build:   (*Factory).WrongKey,
+			wantErr: jwt.ErrInvalidSignature,
+		},
+		"tampered": {
+			build:   (*Factory).Tampered,
+			wantErr: jwt.ErrInvalidSignature,
+		},
+		"wrong-type": {
+			build:   func(f *Factory) *Factory { return f.Type("other+jwt") },
+			wantErr: jwt.ErrInvalidType,
+		},
+	}
+
+	for _, alg := range Algorithms {
+		for tname, tc := range cases {
+			token := tc.build(New(alg).Clock(clock).Claim("sub", "alice")).MustBuild(t)
+
+			for _, v := range []jwt.Verifier{Signer(alg), Verifier(alg)} {
+				var claims struct {
+					Subject string `json:"sub"`
+				}
+				err := jwt.DecodeClaims(token, v, &claims, jwt.WithClock(clock.Now), jwt.WithType("JWT"))
+				if err != tc.wantErr {
+					t.Errorf("%s %s: want %v, got %v", alg, tname, tc.wantErr, err)
+					continue
+				}
+				if err == nil && claims.Subject != "alice" {
+					t.Errorf("%s %s: unexpected claims: %+v", alg, tname, claims)
+				}
+			}
+		}
+	}
+}
+
+func TestFactoryClockExpiration(t *testing.T) {
+	clock := NewClock(time.Now())
+	token := New(HS256).Clock(clock).ExpiresIn(time.Minute).MustBuild(t)
+
+	if err := jwt.DecodeClaims(token, Verifier(HS256), nil, jwt.WithClock(clock.Now)); err != nil {
+		t.Fatalf("cannot decode: %s", err)
+	}
+	clock.Advance(2 * time.Minute)
+	if err := jwt.DecodeClaims(token, Verifier(HS256), nil, jwt.WithClock(clock.Now)); err != jwt.ErrExpired {
+		t.Fatalf("want ErrExpired, got %v", err)
+	}
+}
+
+func TestFactoryUnsupportedAlgorithm(t *testing.T) {
+	if _, err := New("none").Build(); err == nil {
+		t.Fatal("want error for unsupported algorithm")
+	}
+}
+
+func TestKeysNotWeak(t *testing.T) {
+	// keys must be long enough for every algorithm, also when used with
+	// constructors validating key strength
+	for _, key := range [][]byte{HMACKey, otherHMACKey} {
+		for alg, newSigner := range map[string]func([]byte, string, ...jwt.KeyOption) (jwt.Signer, error){
+			HS256: jwt.NewHMAC256,
+			HS384: jwt.NewHMAC384,
+			HS512: jwt.NewHMAC512,
+		} {
+			if _, err := newSigner(key, KeyID); err != nil {
+				t.Errorf("%s: %s", alg, err)
+			}
+		}
+	}
+	for _, key := range []*rsa.PrivateKey{RSAKey, otherRSAKey} {
+		if _, err := jwt.NewRSA512Signer(key, KeyID); err != nil {
+			t.Errorf("RS512: %s", err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Package jwttest provides keys, token factory and fake clock for testing
+// code that uses jwt package.
+//
+// Keys provided by this package are public and must never be used outside of
+// tests.
+package jwttest
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	_ "embed"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/opinary/jwt"
+)
+
+// Supported algorithm names.
+const (
+	HS256 = "HS256"
+	HS384 = "HS384"
+	HS512 = "HS512"
+	RS256 = "RS256"
+	RS384 = "RS384"
+	RS512 = "RS512"
+)
+
+// Algorithms lists all algorithms that keys are provided for.
+var Algorithms = []string{HS256, HS384, HS512, RS256, RS384, RS512}
+
+// KeyID is the key ID ("kid") of all signers returned by this package.
+const KeyID = "jwttest"
+
+var (
+	//go:embed rsa.pem
+	rsaPEM []byte
+
+	//go:embed rsa_other.pem
+	rsaOtherPEM []byte
+)
+
+var (
+	// HMACKey is the secret used by HMAC signers. It is 64 bytes long, as
+	// required for HS512, so that it is not weak for any HMAC algorithm.
+	HMACKey = []byte("jwttest secret, long enough for HS512, do not use in production!")
+
+	// RSAKey is the private key used by RSA signers.
+	RSAKey = mustParseRSA(rsaPEM)
+
+	// otherHMACKey and otherRSAKey are used to sign tokens with invalid
+	// signature.
+	otherHMACKey = []byte("jwttest other secret, long enough for HS512, do not use anywhere")
+	otherRSAKey  = mustParseRSA(rsaOtherPEM)
+)
+
+func mustParseRSA(b []byte) *rsa.PrivateKey {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		panic("jwttest: invalid PEM key")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		panic("jwttest: " + err.Error())
+	}
+	return key
+}
+
+// Signer returns signer for given algorithm, using keys provided by this
+// package and KeyID. It panics if algorithm is not supported.
+func Signer(alg string) jwt.Signer {
+	sig, err := signer(alg, HMACKey, RSAKey, KeyID)
+	if err != nil {
+		panic(err)
+	}
+	return sig
+}
+
+// Verifier returns verifier for given algorithm, matching Signer. For RSA
+// algorithms it holds only the public key. Like Signer, it checks that token
+// key ID is KeyID. It panics if algorithm is not supported.
+func Verifier(alg string) jwt.Verifier {
+	switch alg {
+	case RS256:
+		return &keyedVerifier{Verifier: jwt.RSA256Verifier(&RSAKey.PublicKey), keyID: KeyID}
+	case RS384:
+		return &keyedVerifier{Verifier: jwt.RSA384Verifier(&RSAKey.PublicKey), keyID: KeyID}
+	case RS512:
+		return &keyedVerifier{Verifier: jwt.RSA512Verifier(&RSAKey.PublicKey), keyID: KeyID}
+	}
+	return Signer(alg)
+}
+
+func signer(alg string, hmacKey []byte, rsaKey *rsa.PrivateKey, keyID string) (jwt.Signer, error) {
+	switch alg {
+	case HS256:
+		return jwt.NewHMAC256(hmacKey, keyID)
+	case HS384:
+		return jwt.NewHMAC384(hmacKey, keyID)
+	case HS512:
+		return jwt.NewHMAC512(hmacKey, keyID)
+	case RS256:
+		return jwt.RSA256Signer(rsaKey, keyID), nil
+	case RS384:
+		return jwt.RSA384Signer(rsaKey, keyID), nil
+	case RS512:
+		return jwt.RSA512Signer(rsaKey, keyID), nil
+	}
+	return nil, fmt.Errorf("jwttest: unsupported algorithm %q", alg)
+}
+
+// keyedVerifier attaches key ID to verifier, so that tokens signed with
+// different key ID are rejected.
+type keyedVerifier struct {
+	jwt.Verifier
+	keyID string
+}
+
+func (v *keyedVerifier) KeyID() string {
+	return v.keyID
+}
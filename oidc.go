@@ -0,0 +1,185 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// IDTokenClaims holds claims of OpenID Connect ID token as defined in OpenID
+// Connect Core 1.0, section 2.
+type IDTokenClaims struct {
+	Issuer          string   `json:"iss"`
+	Subject         string   `json:"sub"`
+	Audience        Audience `json:"aud"`
+	ExpirationTime  int64    `json:"exp"`
+	IssuedAt        int64    `json:"iat"`
+	AuthTime        int64    `json:"auth_time,omitempty"`
+	Nonce           string   `json:"nonce,omitempty"`
+	ACR             string   `json:"acr,omitempty"`
+	AMR             []string `json:"amr,omitempty"`
+	AuthorizedParty string   `json:"azp,omitempty"`
+	AccessTokenHash string   `json:"at_hash,omitempty"`
+	CodeHash        string   `json:"c_hash,omitempty"`
+
+	// Raw holds all token claims, so that claims not defined by OpenID
+	// Connect can be decoded as well.
+	Raw json.RawMessage `json:"-"`
+}
+
+// IDTokenVerifier validates ID tokens as described by OpenID Connect Core
+// 1.0, section 3.1.3.7.
+type IDTokenVerifier struct {
+	// Verifier checks token signature. Usually this is KeySet holding
+	// provider keys.
+	Verifier Verifier
+
+	// Issuer is the provider issuer identifier that must exactly match
+	// "iss" claim.
+	Issuer string
+
+	// ClientID is the client identifier that must be present in token
+	// audience.
+	ClientID string
+
+	// MaxAge, if not zero, requires "auth_time" claim and rejects tokens
+	// issued for authentication that happened more than MaxAge ago.
+	MaxAge time.Duration
+
+	// Now is optional (can be nil) function returning current time. It
+	// is useful mostly for testing.
+	Now func() time.Time
+}
+
+// IDTokenParams holds values of the authentication request and response
+// that ID token must be bound to. Empty values are not checked.
+type IDTokenParams struct {
+	// Nonce sent in the authentication request.
+	Nonce string
+
+	// AccessToken returned together with ID token, checked against
+	// "at_hash" claim. If set, the claim is required, as in implicit and
+	// hybrid flows.
+	AccessToken string
+
+	// Code is the authorization code returned together with ID token,
+	// checked against "c_hash" claim. If set, the claim is required, as in
+	// hybrid flow.
+	Code string
+}
+
+// Verify validates ID token and returns its claims. Validation failures of
+// specific claims are reported with ClaimError.
+func (iv *IDTokenVerifier) Verify(token []byte, params IDTokenParams) (*IDTokenClaims, error) {
+	now := iv.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	var raw json.RawMessage
+	err := DecodeClaims(token, iv.Verifier, &raw,
+		WithClock(now),
+		WithRequiredClaims("iss", "sub", "aud", "exp", "iat"),
+		WithIssuer(iv.Issuer),
+		WithAudience(iv.ClientID))
+	if err != nil {
+		return nil, err
+	}
+	var claims IDTokenClaims
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return nil, err
+	}
+	claims.Raw = raw
+
+	// authorized party must be present if there is more than one
+	// audience, and if present must be our client
+	if len(claims.Audience) > 1 && claims.AuthorizedParty == "" {
+		return nil, &ClaimError{Claim: "azp", Err: ErrMissingClaim}
+	}
+	if claims.AuthorizedParty != "" && claims.AuthorizedParty != iv.ClientID {
+		return nil, &ClaimError{Claim: "azp", Err: errors.New("unexpected authorized party")}
+	}
+
+	if params.Nonce != "" && subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(params.Nonce)) != 1 {
+		return nil, &ClaimError{Claim: "nonce", Err: errors.New("nonce mismatch")}
+	}
+
+	if iv.MaxAge != 0 {
+		if claims.AuthTime == 0 {
+			return nil, &ClaimError{Claim: "auth_time", Err: ErrMissingClaim}
+		}
+		if now().After(time.Unix(claims.AuthTime, 0).Add(iv.MaxAge)) {
+			return nil, &ClaimError{Claim: "auth_time", Err: errors.New("authentication too old")}
+		}
+	}
+
+	if params.AccessToken != "" || params.Code != "" {
+		var header struct {
+			Algorithm string `json:"alg"`
+		}
+		if err := DecodeHeader(token, &header); err != nil {
+			return nil, err
+		}
+		if err := checkTokenHash(header.Algorithm, "at_hash", claims.AccessTokenHash, params.AccessToken); err != nil {
+			return nil, err
+		}
+		if err := checkTokenHash(header.Algorithm, "c_hash", claims.CodeHash, params.Code); err != nil {
+			return nil, err
+		}
+	}
+
+	return &claims, nil
+}
+
+// checkTokenHash compares hash claim with the value computed for given
+// token. Hash claim is required only if token value is given.
+func checkTokenHash(alg, claim, want, value string) error {
+	if value == "" {
+		return nil
+	}
+	if want == "" {
+		return &ClaimError{Claim: claim, Err: ErrMissingClaim}
+	}
+	got, err := IDTokenHash(alg, value)
+	if err != nil {
+		return &ClaimError{Claim: claim, Err: err}
+	}
+	if subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+		return &ClaimError{Claim: claim, Err: errors.New("hash mismatch")}
+	}
+	return nil
+}
+
+// IDTokenHash returns "at_hash" or "c_hash" value for given access token or
+// authorization code, as defined in OpenID Connect Core 1.0, section
+// 3.3.2.11: base64 encoded left-most half of the hash computed using hash
+// function of given JWS algorithm.
+func IDTokenHash(alg, value string) (string, error) {
+	h, err := algorithmHash(alg)
+	if err != nil {
+		return "", err
+	}
+	if !h.Available() {
+		return "", ErrAlgorithmNotAvailable
+	}
+	sum := hashSum(h, []byte(value))
+	b, _ := encode(sum[:len(sum)/2])
+	return string(b), nil
+}
+
+// algorithmHash returns hash function used by given JWS algorithm.
+func algorithmHash(alg string) (crypto.Hash, error) {
+	switch {
+	case strings.HasSuffix(alg, "256"):
+		return crypto.SHA256, nil
+	case strings.HasSuffix(alg, "384"):
+		return crypto.SHA384, nil
+	case strings.HasSuffix(alg, "512"):
+		return crypto.SHA512, nil
+	default:
+		return 0, ErrAlgorithmNotAvailable
+	}
+}
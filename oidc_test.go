@@ -0,0 +1,157 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIDTokenHash(t *testing.T) {
+	// example from OpenID Connect Core 1.0, appendix A.4
+	got, err := IDTokenHash("RS256", "Qcb0Orv1zh30vL1MPRsbm-diHiMwcLyZvn1arpZv-Jxf_11jnpEX3Tgfvk")
+	if err != nil {
+		t.Fatalf("cannot compute hash: %s", err)
+	}
+	if want := "LDktKdoQak3Pk0cnXxCltA"; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+
+	if _, err := IDTokenHash("none", "x"); err != ErrAlgorithmNotAvailable {
+		t.Fatalf("want ErrAlgorithmNotAvailable, got %v", err)
+	}
+}
+
+func TestIDTokenVerifier(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	signer := RSA256Signer(privRSA, "oidc-key")
+	atHash, _ := IDTokenHash("RS256", "access-token")
+	cHash, _ := IDTokenHash("RS256", "code")
+
+	valid := func() IDTokenClaims {
+		return IDTokenClaims{
+			Issuer:          "https://op.example.com",
+			Subject:         "248289761001",
+			Audience:        Audience{"client"},
+			ExpirationTime:  now.Add(time.Hour).Unix(),
+			IssuedAt:        now.Unix(),
+			AuthTime:        now.Add(-time.Minute).Unix(),
+			Nonce:           "n-0S6_WzA2Mj",
+			AccessTokenHash: atHash,
+			CodeHash:        cHash,
+		}
+	}
+	params := IDTokenParams{
+		Nonce:       "n-0S6_WzA2Mj",
+		AccessToken: "access-token",
+		Code:        "code",
+	}
+
+	cases := map[string]struct {
+		modify    func(*IDTokenClaims)
+		params    IDTokenParams
+		wantClaim string
+		wantErr   error
+	}{
+		"ok": {
+			params: params,
+		},
+		"ok-multiple-audiences": {
+			modify: func(c *IDTokenClaims) {
+				c.Audience = Audience{"client", "other"}
+				c.AuthorizedParty = "client"
+			},
+			params: params,
+		},
+		"expired": {
+			modify:  func(c *IDTokenClaims) { c.ExpirationTime = now.Add(-time.Second).Unix() },
+			wantErr: ErrExpired,
+		},
+		"invalid-issuer": {
+			modify:    func(c *IDTokenClaims) { c.Issuer = "https://evil.example.com" },
+			wantClaim: "iss",
+		},
+		"invalid-audience": {
+			modify:    func(c *IDTokenClaims) { c.Audience = Audience{"other"} },
+			wantClaim: "aud",
+		},
+		"missing-azp": {
+			modify:    func(c *IDTokenClaims) { c.Audience = Audience{"client", "other"} },
+			wantClaim: "azp",
+		},
+		"invalid-azp": {
+			modify:    func(c *IDTokenClaims) { c.AuthorizedParty = "other" },
+			wantClaim: "azp",
+		},
+		"invalid-nonce": {
+			params:    IDTokenParams{Nonce: "other"},
+			wantClaim: "nonce",
+		},
+		"old-authentication": {
+			modify:    func(c *IDTokenClaims) { c.AuthTime = now.Add(-time.Hour).Unix() },
+			wantClaim: "auth_time",
+		},
+		"invalid-at-hash": {
+			params:    IDTokenParams{AccessToken: "other"},
+			wantClaim: "at_hash",
+		},
+		"invalid-c-hash": {
+			params:    IDTokenParams{Code: "other"},
+			wantClaim: "c_hash",
+		},
+		"missing-at-hash": {
+			modify:    func(c *IDTokenClaims) { c.AccessTokenHash = "" },
+			params:    params,
+			wantClaim: "at_hash",
+			wantErr:   ErrMissingClaim,
+		},
+		"missing-c-hash": {
+			modify:    func(c *IDTokenClaims) { c.CodeHash = "" },
+			params:    params,
+			wantClaim: "c_hash",
+			wantErr:   ErrMissingClaim,
+		},
+		"ok-no-hashes-expected": {
+			modify: func(c *IDTokenClaims) {
+				c.AccessTokenHash = ""
+				c.CodeHash = ""
+			},
+			params: IDTokenParams{Nonce: params.Nonce},
+		},
+	}
+
+	iv := &IDTokenVerifier{
+		Verifier: signer,
+		Issuer:   "https://op.example.com",
+		ClientID: "client",
+		MaxAge:   10 * time.Minute,
+		Now:      func() time.Time { return now },
+	}
+
+	for tname, tc := range cases {
+		claims := valid()
+		if tc.modify != nil {
+			tc.modify(&claims)
+		}
+		token, err := Encode(signer, &claims)
+		if err != nil {
+			t.Fatalf("%s: cannot encode: %s", tname, err)
+		}
+
+		got, err := iv.Verify(token, tc.params)
+		if tc.wantClaim == "" && tc.wantErr == nil {
+			if err != nil {
+				t.Errorf("%s: want no error, got %q", tname, err)
+			} else if got.Subject != claims.Subject || len(got.Raw) == 0 {
+				t.Errorf("%s: unexpected claims: %+v", tname, got)
+			}
+			continue
+		}
+		if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: want %q error, got %q", tname, tc.wantErr, err)
+		}
+		var cerr *ClaimError
+		if tc.wantClaim != "" && (!errors.As(err, &cerr) || cerr.Claim != tc.wantClaim) {
+			t.Errorf("%s: want %q claim error, got %q", tname, tc.wantClaim, err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Token is JWT token parsed without signature verification.
+//
+// None of the Token content can be trusted, because anyone can create token
+// with any content. It must be used only for decisions that are made before
+// verification, like routing, logging or selecting verification key. Use
+// DecodeClaims to get trusted claims.
+type Token struct {
+	// Header is decoded JOSE header.
+	Header map[string]interface{}
+
+	// Claims is decoded, but unverified claims JSON.
+	Claims json.RawMessage
+
+	// Signature is decoded signature.
+	Signature []byte
+
+	// SigningInput is the part of the token that signature is computed
+	// for: encoded header and claims, separated by dot.
+	SigningInput []byte
+}
+
+// Algorithm returns "alg" header value or empty string.
+func (t *Token) Algorithm() string {
+	s, _ := t.Header["alg"].(string)
+	return s
+}
+
+// KeyID returns "kid" header value or empty string.
+func (t *Token) KeyID() string {
+	s, _ := t.Header["kid"].(string)
+	return s
+}
+
+// ParseUnverified split given JWT token and decode all of its parts without
+// verifying signature. Returned Token must not be trusted.
+//
+// Segments are decoded in strict mode, unless disabled using
+// WithStrictEncoding option. DefaultLimits are enforced, unless changed using
+// WithLimits option. Other options are ignored.
+func ParseUnverified(token []byte, opts ...DecodeOption) (*Token, error) {
+	conf := decodeOptions{strict: true, limits: DefaultLimits}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	if conf.limits.MaxTokenSize > 0 && len(token) > conf.limits.MaxTokenSize {
+		return nil, ErrTokenTooLarge
+	}
+	parts, err := splitToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := decodeSegment(parts.header, conf.strict)
+	if err != nil {
+		return nil, &SegmentError{Segment: "header", Err: err}
+	}
+	if conf.limits.MaxHeaderSize > 0 && len(b) > conf.limits.MaxHeaderSize {
+		return nil, ErrHeaderTooLarge
+	}
+	if err := conf.limits.scanObject(b, nil); err != nil {
+		return nil, jsonError("header", err)
+	}
+	var header map[string]interface{}
+	if err := json.Unmarshal(b, &header); err != nil {
+		return nil, fmt.Errorf("cannot JSON decode header: %s", err)
+	}
+
+	claims, err := decodeSegment(parts.claims, conf.strict)
+	if err != nil {
+		return nil, &SegmentError{Segment: "claims", Err: err}
+	}
+	if conf.limits.MaxClaimsSize > 0 && len(claims) > conf.limits.MaxClaimsSize {
+		return nil, ErrClaimsTooLarge
+	}
+	if err := conf.limits.scanObject(claims, nil); err != nil {
+		return nil, jsonError("claims", err)
+	}
+
+	signature, err := decodeSegment(parts.signature, conf.strict)
+	if err != nil {
+		return nil, &SegmentError{Segment: "signature", Err: err}
+	}
+
+	return &Token{
+		Header:       header,
+		Claims:       claims,
+		Signature:    signature,
+		SigningInput: append([]byte{}, parts.signingInput...),
+	}, nil
+}
+
+// tokenParts holds still encoded parts of the JWT token. All slices share
+// memory with the token.
+type tokenParts struct {
+	header       []byte
+	claims       []byte
+	signature    []byte
+	signingInput []byte
+}
+
+// splitToken split token using compact serialization into its parts.
+// ErrMalformedToken is returned if token does not consist of exactly three
+// parts.
+func splitToken(token []byte) (tokenParts, error) {
+	first := bytes.IndexByte(token, '.')
+	if first < 0 {
+		return tokenParts{}, ErrMalformedToken
+	}
+	second := bytes.IndexByte(token[first+1:], '.')
+	if second < 0 {
+		return tokenParts{}, ErrMalformedToken
+	}
+	second += first + 1
+	if bytes.IndexByte(token[second+1:], '.') >= 0 {
+		return tokenParts{}, ErrMalformedToken
+	}
+	return tokenParts{
+		header:       token[:first],
+		claims:       token[first+1 : second],
+		signature:    token[second+1:],
+		signingInput: token[:second],
+	}, nil
+}
@@ -0,0 +1,54 @@
+package jwt
+
+import (
+	"crypto"
+	"hash"
+	"sync"
+)
+
+// bufferPool holds buffers used for decoding tokens. Buffers bigger than
+// maxPooledBuffer are not returned to the pool, so that single huge token
+// does not keep memory allocated forever.
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new([]byte) },
+}
+
+const maxPooledBuffer = 16 << 10
+
+// getBuffer returns buffer of given size from the pool. Content of the buffer
+// is not zeroed.
+func getBuffer(size int) *[]byte {
+	b := bufferPool.Get().(*[]byte)
+	if cap(*b) < size {
+		*b = make([]byte, size)
+	}
+	*b = (*b)[:size]
+	return b
+}
+
+func putBuffer(b *[]byte) {
+	if cap(*b) <= maxPooledBuffer {
+		bufferPool.Put(b)
+	}
+}
+
+// hashPools holds hash states for every hash function, indexed by
+// crypto.Hash value.
+var hashPools [crypto.BLAKE2b_512 + 1]sync.Pool
+
+// hashSum returns digest of given data, computed using pooled hash state.
+// Hash function must be available.
+func hashSum(h crypto.Hash, data []byte) []byte {
+	pool := &hashPools[h]
+	hasher, ok := pool.Get().(hash.Hash)
+	if ok {
+		hasher.Reset()
+	} else {
+		hasher = h.New()
+	}
+	// hash.Hash Write never returns an error
+	hasher.Write(data)
+	sum := hasher.Sum(nil)
+	pool.Put(hasher)
+	return sum
+}
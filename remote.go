@@ -0,0 +1,243 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RemoteKeySet is KeySet that fetches JSON Web Key Set from remote URL, for
+// example OpenID Connect provider "jwks_uri". Keys are fetched on first use
+// and fetched again when token is signed with unknown key ID, but not more
+// often than MinRefreshInterval. This applies to failed fetches as well, so
+// that provider which is down is not flooded with requests.
+type RemoteKeySet struct {
+	url        string
+	client     *http.Client
+	algorithms []string
+
+	// MinRefreshInterval is the minimum time between two fetches of the
+	// key set, so that tokens with random key IDs cannot be used to flood
+	// key set provider with requests.
+	MinRefreshInterval time.Duration
+
+	// Now is optional (can be nil) function returning current time. It
+	// is useful mostly for testing.
+	Now func() time.Time
+
+	mu        sync.Mutex
+	keys      *JWKSet
+	attempted time.Time
+	fetchErr  error
+	fetch     *keySetFetch
+}
+
+// keySetFetch is the key set fetch in progress. It is shared by all callers
+// waiting for the key set, so that only one request is sent at a time.
+type keySetFetch struct {
+	done chan struct{}
+	keys *JWKSet
+	err  error
+}
+
+var _ ContextKeySet = (*RemoteKeySet)(nil)
+
+// NewRemoteKeySet returns key set fetching keys from given URL.
+//
+// client is optional (can be nil) HTTP client, http.DefaultClient is used
+// otherwise. If any algorithm is given, only tokens signed with one of them
+// are accepted.
+func NewRemoteKeySet(url string, client *http.Client, algorithms ...string) *RemoteKeySet {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &RemoteKeySet{
+		url:                url,
+		client:             client,
+		algorithms:         algorithms,
+		MinRefreshInterval: time.Minute,
+	}
+}
+
+// Algorithm returns empty string, because key set is not bound to a single
+// algorithm.
+func (r *RemoteKeySet) Algorithm() string {
+	return ""
+}
+
+// Verify returns nil if signature can be verified by any key from the set,
+// using one of allowed algorithms.
+func (r *RemoteKeySet) Verify(signature, data []byte) error {
+	return r.VerifyContext(context.Background(), signature, data)
+}
+
+// VerifyContext is like Verify, but given context is used if key set must
+// be fetched.
+func (r *RemoteKeySet) VerifyContext(ctx context.Context, signature, data []byte) error {
+	keys, err := r.cached(ctx, false)
+	if err != nil {
+		return err
+	}
+	if len(r.algorithms) != 0 {
+		allowed := &JWKSet{}
+		for _, k := range keys.Keys {
+			if algorithmAllowed(r.algorithms, k.Algorithm) {
+				allowed.Keys = append(allowed.Keys, k)
+			}
+		}
+		keys = allowed
+	}
+	return keys.Verify(signature, data)
+}
+
+// Lookup returns verifier for the key with given ID and algorithm. Key set is
+// fetched again if key is not known.
+func (r *RemoteKeySet) Lookup(alg, keyID string) (Verifier, error) {
+	return r.LookupContext(context.Background(), alg, keyID)
+}
+
+// LookupContext is like Lookup, but given context is used if key set must
+// be fetched.
+func (r *RemoteKeySet) LookupContext(ctx context.Context, alg, keyID string) (Verifier, error) {
+	if !algorithmAllowed(r.algorithms, alg) {
+		return nil, ErrInvalidSigner
+	}
+
+	keys, err := r.cached(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	if v, err := keys.Lookup(alg, keyID); err == nil {
+		return v, nil
+	}
+
+	// key might have been rotated since last fetch
+	keys, err = r.cached(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	return keys.Lookup(alg, keyID)
+}
+
+// cached returns cached key set, fetching it if necessary. If refresh is
+// true, key set is fetched again unless it was fetched recently. If the last
+// fetch failed recently, its error is returned without fetching again,
+// unless key set fetched before can be returned instead.
+//
+// Fetch is done without holding the lock. Callers arriving while fetch is in
+// progress wait for its result, but stop waiting once their context is done.
+func (r *RemoteKeySet) cached(ctx context.Context, refresh bool) (*JWKSet, error) {
+	for {
+		r.mu.Lock()
+		now := time.Now()
+		if r.Now != nil {
+			now = r.Now()
+		}
+		recent := now.Sub(r.attempted) < r.MinRefreshInterval
+		if r.keys != nil && (!refresh || recent) {
+			keys := r.keys
+			r.mu.Unlock()
+			return keys, nil
+		}
+		if r.fetchErr != nil && recent {
+			err := r.fetchErr
+			r.mu.Unlock()
+			return nil, err
+		}
+		if f := r.fetch; f != nil {
+			r.mu.Unlock()
+			select {
+			case <-f.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			// fetch failed only because its caller gave up, so it
+			// must be tried again
+			if isContextError(f.err) && ctx.Err() == nil {
+				continue
+			}
+			return f.keys, f.err
+		}
+		f := &keySetFetch{done: make(chan struct{})}
+		r.fetch = f
+		r.mu.Unlock()
+
+		var keys JWKSet
+		if err := getJSON(ctx, r.client, r.url, &keys); err != nil {
+			f.err = fmt.Errorf("cannot fetch key set: %w", err)
+		} else {
+			f.keys = &keys
+		}
+
+		r.mu.Lock()
+		// fetch canceled by its caller says nothing about the provider
+		if !isContextError(f.err) {
+			if f.keys != nil {
+				r.keys = f.keys
+			}
+			r.fetchErr = f.err
+			r.attempted = now
+		}
+		r.fetch = nil
+		r.mu.Unlock()
+		close(f.done)
+		return f.keys, f.err
+	}
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// maxRemoteDocument is the maximum size of fetched JSON document.
+const maxRemoteDocument = 1 << 20
+
+// getJSON fetches JSON document from given URL and decodes it into given
+// value.
+func getJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	return doJSON(client, req, v)
+}
+
+// doJSON sends given request and decodes JSON response into given value.
+func doJSON(client *http.Client, req *http.Request, v interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &statusError{code: resp.StatusCode}
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteDocument+1))
+	if err != nil {
+		return err
+	}
+	if len(b) > maxRemoteDocument {
+		return errors.New("response too big")
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("cannot JSON decode response: %s", err)
+	}
+	return nil
+}
+
+// statusError is returned when remote document cannot be fetched because of
+// unexpected response status.
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected response status: %d", e.code)
+}
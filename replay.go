@@ -0,0 +1,106 @@
+package jwt
+
+import (
+	"sync"
+	"time"
+)
+
+// ReplayStore is the interface implemented by objects that keep track of
+// used and revoked token IDs ("jti").
+//
+// Zero expiration time means that token never expires and its ID must be
+// remembered forever.
+type ReplayStore interface {
+	// Use marks token ID as used until given expiration time. ErrReplayed
+	// is returned if token ID was already used and ErrRevoked if it was
+	// revoked.
+	Use(tokenID string, exp time.Time) error
+
+	// Revoke marks token ID as revoked until given expiration time, so
+	// that token is rejected even if it was never used before.
+	Revoke(tokenID string, exp time.Time) error
+}
+
+// MemoryReplayStore is ReplayStore implementation that keeps all token IDs in
+// memory. Entries are removed once token expires.
+//
+// Entries with zero expiration time are kept for the lifetime of the store,
+// as required by ReplayStore. Memory used by the store grows with every such
+// token, so tokens without expiration time should be rejected before they
+// reach it, for example using WithRequiredClaims("exp").
+type MemoryReplayStore struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]replayEntry
+	lastSweep time.Time
+}
+
+type replayEntry struct {
+	exp     time.Time
+	revoked bool
+}
+
+var _ ReplayStore = (*MemoryReplayStore)(nil)
+
+// NewMemoryReplayStore returns empty in memory replay store.
+//
+// now is optional (can be nil) function returning current time. It is useful
+// mostly for testing.
+func NewMemoryReplayStore(now func() time.Time) *MemoryReplayStore {
+	if now == nil {
+		now = time.Now
+	}
+	return &MemoryReplayStore{
+		now:     now,
+		entries: make(map[string]replayEntry),
+	}
+}
+
+// Use marks token ID as used until given expiration time. ErrReplayed is
+// returned if token ID was already used and ErrRevoked if it was revoked,
+// unless that entry has already expired.
+func (s *MemoryReplayStore) Use(tokenID string, exp time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if e, ok := s.entries[tokenID]; ok && !e.expired(now) {
+		if e.revoked {
+			return ErrRevoked
+		}
+		return ErrReplayed
+	}
+	s.entries[tokenID] = replayEntry{exp: exp}
+	return nil
+}
+
+// Revoke marks token ID as revoked until given expiration time. Revocation
+// replaces any entry of token ID, including the one marking it as used.
+func (s *MemoryReplayStore) Revoke(tokenID string, exp time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(s.now())
+	s.entries[tokenID] = replayEntry{exp: exp, revoked: true}
+	return nil
+}
+
+// sweep removes expired entries, but not more often than once a minute.
+func (s *MemoryReplayStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for id, e := range s.entries {
+		if e.expired(now) {
+			delete(s.entries, id)
+		}
+	}
+}
+
+func (e replayEntry) expired(now time.Time) bool {
+	return !e.exp.IsZero() && now.After(e.exp)
+}
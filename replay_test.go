@@ -0,0 +1,66 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecodeClaimsReplay(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+	store := NewMemoryReplayStore(clock)
+	signer := HMAC256([]byte("top secret 9921842"), "")
+
+	type claim struct {
+		TokenID        string `json:"jti,omitempty"`
+		ExpirationTime int64  `json:"exp,omitempty"`
+	}
+	mustEncode := func(c claim) []byte {
+		token, err := Encode(signer, c)
+		if err != nil {
+			t.Fatalf("cannot encode: %s", err)
+		}
+		return token
+	}
+
+	exp := now.Add(time.Minute).Unix()
+	first := mustEncode(claim{TokenID: "first", ExpirationTime: exp})
+	revoked := mustEncode(claim{TokenID: "revoked", ExpirationTime: exp})
+	anonymous := mustEncode(claim{ExpirationTime: exp})
+
+	if err := store.Revoke("revoked", time.Unix(exp, 0)); err != nil {
+		t.Fatalf("cannot revoke: %s", err)
+	}
+
+	var c claim
+	if err := DecodeClaims(first, signer, &c, WithReplayStore(store), WithClock(clock)); err != nil {
+		t.Fatalf("cannot decode: %s", err)
+	}
+	if err := DecodeClaims(first, signer, &c, WithReplayStore(store), WithClock(clock)); err != ErrReplayed {
+		t.Fatalf("want ErrReplayed, got %v", err)
+	}
+	if err := DecodeClaims(revoked, signer, &c, WithReplayStore(store), WithClock(clock)); err != ErrRevoked {
+		t.Fatalf("want ErrRevoked, got %v", err)
+	}
+	if err := DecodeClaims(anonymous, signer, &c, WithReplayStore(store), WithClock(clock)); err != ErrMissingTokenID {
+		t.Fatalf("want ErrMissingTokenID, got %v", err)
+	}
+
+	// once token expired, its ID is forgotten
+	now = now.Add(2 * time.Minute)
+	if err := store.Use("first", time.Time{}); err != nil {
+		t.Fatalf("want expired entry removed, got %v", err)
+	}
+	if n := len(store.entries); n != 1 {
+		t.Fatalf("want 1 entry, got %d", n)
+	}
+
+	// token without expiration time is remembered forever
+	now = now.Add(24 * 365 * time.Hour)
+	if err := store.Use("first", time.Time{}); err != ErrReplayed {
+		t.Fatalf("want ErrReplayed, got %v", err)
+	}
+	if n := len(store.entries); n != 1 {
+		t.Fatalf("want 1 entry, got %d", n)
+	}
+}
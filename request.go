@@ -0,0 +1,170 @@
+package jwt
+
+import (
+	"bytes"
+	"errors"
+	"time"
+)
+
+// RequestObjectType is the token type ("typ") header value of authorization
+// request objects as defined in RFC 9101, section 10.8.
+const RequestObjectType = "oauth-authz-req+jwt"
+
+// ErrEncryptedRequestObject is returned when verifying request object
+// encrypted using JWE compact serialization, while verifier has no
+// Decrypter.
+var ErrEncryptedRequestObject = errors.New("encrypted request objects are not supported")
+
+// NewRequestObject returns signed request object carrying given
+// authorization request parameters, for example "response_type",
+// "redirect_uri", "scope", "state" or "nonce". Audience is the authorization
+// server issuer identifier. Request object is valid for given lifetime.
+//
+// Signed request object can be additionally encrypted using
+// EncryptRequestObject.
+func NewRequestObject(sig Signer, clientID, audience string, params map[string]interface{}, lifetime time.Duration) ([]byte, error) {
+	if sig.Algorithm() == "none" {
+		return nil, errors.New("request object must be signed")
+	}
+	if _, ok := params["request"]; ok {
+		return nil, errors.New("request object must not contain request parameter")
+	}
+	if _, ok := params["request_uri"]; ok {
+		return nil, errors.New("request object must not contain request_uri parameter")
+	}
+	tokenID, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := make(map[string]interface{}, len(params)+6)
+	for name, value := range params {
+		claims[name] = value
+	}
+	now := time.Now()
+	claims["iss"] = clientID
+	claims["aud"] = audience
+	claims["client_id"] = clientID
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(lifetime).Unix()
+	claims["jti"] = tokenID
+	return Encode(sig, claims, EncodeWithType(RequestObjectType))
+}
+
+// EncryptRequestObject returns signed request object encrypted for the
+// authorization server, as described by RFC 9101, section 4. Encrypter
+// usually holds authorization server public key and encryption is the
+// content encryption algorithm ("enc"), for example "A128CBC-HS256".
+func EncryptRequestObject(e Encrypter, encryption string, requestObject []byte) ([]byte, error) {
+	if bytes.Count(requestObject, []byte{'.'}) != 2 {
+		return nil, errors.New("request object must be signed")
+	}
+	return Encrypt(e, encryption, "JWT", requestObject)
+}
+
+// RequestObjectVerifier validates request objects on the authorization
+// server, as described by RFC 9101, section 6.
+type RequestObjectVerifier struct {
+	// Issuer is the authorization server issuer identifier that must be
+	// present in request object audience.
+	Issuer string
+
+	// Keys returns verifier of the given client request objects, using
+	// client registered key.
+	Keys func(clientID string) (Verifier, error)
+
+	// Decrypter is optional authorization server key, used to decrypt
+	// encrypted request objects. If nil, encrypted request objects are
+	// rejected.
+	Decrypter Decrypter
+
+	// Store is optional ReplayStore, used to reject request objects used
+	// more than once.
+	Store ReplayStore
+
+	// Now is optional (can be nil) function returning current time. It
+	// is useful mostly for testing.
+	Now func() time.Time
+}
+
+// Verify validates request object sent with the authorization request and
+// returns authorization request parameters it contains. clientID is the
+// "client_id" parameter of the outer authorization request, that must match
+// request object "client_id" claim. Parameters of the outer request must
+// be ignored in favor of the returned ones.
+// Encrypted request objects are decrypted using Decrypter and must contain
+// signed request object. If Decrypter is nil, they are rejected with
+// ErrEncryptedRequestObject.
+func (rv *RequestObjectVerifier) Verify(requestObject []byte, clientID string) (map[string]interface{}, error) {
+	now := time.Now
+	if rv.Now != nil {
+		now = rv.Now
+	}
+	if clientID == "" {
+		return nil, errors.New("client_id parameter is required")
+	}
+	if rv.Keys == nil {
+		return nil, errors.New("client keys are required")
+	}
+	// JWE compact serialization consists of five parts
+	if bytes.Count(requestObject, []byte{'.'}) == 4 {
+		if rv.Decrypter == nil {
+			return nil, ErrEncryptedRequestObject
+		}
+		payload, err := Decrypt(rv.Decrypter, requestObject)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Count(payload, []byte{'.'}) != 2 {
+			return nil, errors.New("encrypted request object must be signed")
+		}
+		requestObject = payload
+	}
+	v, err := rv.Keys(clientID)
+	if err != nil {
+		return nil, err
+	}
+	if v.Algorithm() == "none" {
+		return nil, ErrInvalidSigner
+	}
+
+	opts := []DecodeOption{
+		WithClock(now),
+		WithType(RequestObjectType),
+		WithRequiredClaims("client_id", "aud"),
+		WithAudience(rv.Issuer),
+		WithClaimValidator("client_id", func(claims map[string]interface{}) error {
+			if claims["client_id"] != clientID {
+				return errors.New("client_id parameter mismatch")
+			}
+			return nil
+		}),
+		WithClaimValidator("iss", func(claims map[string]interface{}) error {
+			if iss, ok := claims["iss"]; ok && iss != clientID {
+				return errors.New("issuer must be the client")
+			}
+			return nil
+		}),
+		WithClaimValidator("request", rejectClaim("request")),
+		WithClaimValidator("request_uri", rejectClaim("request_uri")),
+	}
+	if rv.Store != nil {
+		opts = append(opts, WithReplayStore(rv.Store))
+	}
+	var params map[string]interface{}
+	if err := DecodeClaims(requestObject, v, &params, opts...); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// rejectClaim returns ClaimValidator failing if claim with given name is
+// present.
+func rejectClaim(name string) ClaimValidator {
+	return func(claims map[string]interface{}) error {
+		if _, ok := claims[name]; ok {
+			return errors.New("claim not allowed")
+		}
+		return nil
+	}
+}
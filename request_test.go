@@ -0,0 +1,207 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRequestObject(t *testing.T) {
+	const issuer = "https://as.example.com"
+	clientKey := RSA256Signer(privRSA, "client-key")
+
+	rv := &RequestObjectVerifier{
+		Issuer: issuer,
+		Keys: func(clientID string) (Verifier, error) {
+			if clientID == "client" {
+				return clientKey, nil
+			}
+			return nil, errors.New("unknown client")
+		},
+		Store: NewMemoryReplayStore(nil),
+	}
+
+	params := map[string]interface{}{
+		"response_type": "code",
+		"redirect_uri":  "https://client.example.com/cb",
+		"scope":         "openid profile",
+		"state":         "af0ifjsldkj",
+	}
+	req, err := NewRequestObject(clientKey, "client", issuer, params, time.Minute)
+	if err != nil {
+		t.Fatalf("cannot create request object: %s", err)
+	}
+	got, err := rv.Verify(req, "client")
+	if err != nil {
+		t.Fatalf("cannot verify: %s", err)
+	}
+	for name, value := range params {
+		if got[name] != value {
+			t.Errorf("want %s=%v, got %v", name, value, got[name])
+		}
+	}
+	if _, err := rv.Verify(req, "client"); err != ErrReplayed {
+		t.Fatalf("want ErrReplayed, got %v", err)
+	}
+
+	cases := map[string]struct {
+		request   func() ([]byte, error)
+		clientID  string
+		wantClaim string
+		wantErr   error
+	}{
+		"client-id-mismatch": {
+			request: func() ([]byte, error) {
+				return Encode(clientKey, map[string]interface{}{
+					"client_id": "other",
+					"aud":       issuer,
+				}, EncodeWithType(RequestObjectType))
+			},
+			wantClaim: "client_id",
+		},
+		"issuer-not-client": {
+			request: func() ([]byte, error) {
+				return Encode(clientKey, map[string]interface{}{
+					"iss":       "other",
+					"client_id": "client",
+					"aud":       issuer,
+				}, EncodeWithType(RequestObjectType))
+			},
+			wantClaim: "iss",
+		},
+		"wrong-audience": {
+			request: func() ([]byte, error) {
+				return NewRequestObject(clientKey, "client", "https://other.example.com", nil, time.Minute)
+			},
+			wantClaim: "aud",
+		},
+		"nested-request-uri": {
+			request: func() ([]byte, error) {
+				return Encode(clientKey, map[string]interface{}{
+					"client_id":   "client",
+					"aud":         issuer,
+					"request_uri": "https://client.example.com/request",
+				}, EncodeWithType(RequestObjectType))
+			},
+			wantClaim: "request_uri",
+		},
+		"plain-jwt": {
+			request: func() ([]byte, error) {
+				return Encode(clientKey, map[string]interface{}{
+					"client_id": "client",
+					"aud":       issuer,
+				})
+			},
+			wantErr: ErrInvalidType,
+		},
+		"wrong-algorithm": {
+			request: func() ([]byte, error) {
+				return NewRequestObject(HMAC256([]byte("guess"), "client-key"), "client", issuer, nil, time.Minute)
+			},
+			wantErr: ErrInvalidSigner,
+		},
+		"expired": {
+			request: func() ([]byte, error) {
+				return NewRequestObject(clientKey, "client", issuer, nil, -time.Minute)
+			},
+			wantErr: ErrExpired,
+		},
+		"encrypted": {
+			request: func() ([]byte, error) {
+				// RFC 7516, appendix A.3
+				return []byte("eyJhbGciOiJBMTI4S1ciLCJlbmMiOiJBMTI4Q0JDLUhTMjU2In0." +
+					"6KB707dM9YTIgHtLvtgWQ8mKwboJW3of9locizkDTHzBC2IlrT1oOQ." +
+					"AxY8DCtDaGlsbGljb3RoZQ." +
+					"KDlTtXchhZTGufMYmOYGS4HffxPSUrfmqCHXaI9wOGY." +
+					"U0m_YmjN04DJvceFICbCVQ"), nil
+			},
+			wantErr: ErrEncryptedRequestObject,
+		},
+	}
+
+	for tname, tc := range cases {
+		req, err := tc.request()
+		if err != nil {
+			t.Fatalf("%s: cannot create request object: %s", tname, err)
+		}
+		clientID := tc.clientID
+		if clientID == "" {
+			clientID = "client"
+		}
+		_, err = rv.Verify(req, clientID)
+		if tc.wantClaim != "" {
+			var cerr *ClaimError
+			if !errors.As(err, &cerr) || cerr.Claim != tc.wantClaim {
+				t.Errorf("%s: want %q claim error, got %v", tname, tc.wantClaim, err)
+			}
+			continue
+		}
+		if err != tc.wantErr {
+			t.Errorf("%s: want %v, got %v", tname, tc.wantErr, err)
+		}
+	}
+
+	if _, err := NewRequestObject(clientKey, "client", issuer, map[string]interface{}{"request_uri": "x"}, time.Minute); err == nil {
+		t.Fatal("want error for nested request_uri")
+	}
+}
+
+func TestRequestObjectEncrypted(t *testing.T) {
+	const issuer = "https://as.example.com"
+	clientKey := RSA256Signer(privRSA, "client-key")
+	serverKey := rfc7520RSAKey(t)
+
+	rv := &RequestObjectVerifier{
+		Issuer: issuer,
+		Keys: func(clientID string) (Verifier, error) {
+			return clientKey, nil
+		},
+		Decrypter: RSAOAEP256Decrypter(serverKey, "as-enc"),
+	}
+
+	req, err := NewRequestObject(clientKey, "client", issuer, map[string]interface{}{"state": "af0ifjsldkj"}, time.Minute)
+	if err != nil {
+		t.Fatalf("cannot create request object: %s", err)
+	}
+	encrypted, err := EncryptRequestObject(RSAOAEP256Encrypter(&serverKey.PublicKey, "as-enc"), "A128CBC-HS256", req)
+	if err != nil {
+		t.Fatalf("cannot encrypt request object: %s", err)
+	}
+	got, err := rv.Verify(encrypted, "client")
+	if err != nil {
+		t.Fatalf("cannot verify: %s", err)
+	}
+	if got["state"] != "af0ifjsldkj" {
+		t.Fatalf("unexpected parameters: %v", got)
+	}
+
+	if _, err := EncryptRequestObject(RSAOAEP256Encrypter(&serverKey.PublicKey, ""), "A128CBC-HS256", []byte(`{"client_id":"client"}`)); err == nil {
+		t.Fatal("want error for unsigned request object")
+	}
+	unsigned, err := Encrypt(RSAOAEP256Encrypter(&serverKey.PublicKey, "as-enc"), "A128GCM", "", []byte(`{"client_id":"client"}`))
+	if err != nil {
+		t.Fatalf("cannot encrypt: %s", err)
+	}
+	if _, err := rv.Verify(unsigned, "client"); err == nil {
+		t.Fatal("want error for unsigned encrypted request object")
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate key: %s", err)
+	}
+	other, err := EncryptRequestObject(RSAOAEP256Encrypter(&otherKey.PublicKey, "as-enc"), "A128GCM", req)
+	if err != nil {
+		t.Fatalf("cannot encrypt request object: %s", err)
+	}
+	if _, err := rv.Verify(other, "client"); err != ErrDecryption {
+		t.Fatalf("want ErrDecryption, got %v", err)
+	}
+
+	noKeys := &RequestObjectVerifier{Issuer: issuer}
+	if _, err := noKeys.Verify(req, "client"); err == nil {
+		t.Fatal("want error for verifier without keys")
+	}
+}
@@ -0,0 +1,206 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RotatingSigner is a signer that holds several keys, each with its own
+// activation time, and always signs using the key that is currently active.
+//
+// Key that is not yet active (next key) is already part of the verification
+// set, so that it can be published before it is used. Once replaced by newer
+// key, retired key is still accepted for verification until grace period
+// passes, so that tokens signed before the rotation remain valid.
+//
+// Every key must provide non empty key ID, so that verifiers can tell keys
+// apart.
+type RotatingSigner struct {
+	grace time.Duration
+	now   func() time.Time
+
+	mu   sync.RWMutex
+	keys []rotationKey
+}
+
+type rotationKey struct {
+	signer     Signer
+	keyID      string
+	activeFrom time.Time
+}
+
+var _ Signer = (*RotatingSigner)(nil)
+var _ KeySet = (*RotatingSigner)(nil)
+
+// NewRotatingSigner returns signer without any key. Use Add to register keys.
+//
+// grace is the time retired key is still accepted for verification after
+// being replaced. Usually it should not be shorter than the lifetime of
+// issued tokens.
+//
+// now is optional (can be nil) function returning current time. It is useful
+// mostly for testing.
+func NewRotatingSigner(grace time.Duration, now func() time.Time) *RotatingSigner {
+	if now == nil {
+		now = time.Now
+	}
+	return &RotatingSigner{
+		grace: grace,
+		now:   now,
+	}
+}
+
+// Add registers signer that becomes active at given time. Keys that are
+// retired and no longer within grace period are removed.
+func (r *RotatingSigner) Add(sig Signer, activeFrom time.Time) error {
+	named, ok := sig.(namedKeyHolder)
+	if !ok || named.KeyID() == "" {
+		return errors.New("signer must provide key ID")
+	}
+	keyID := named.KeyID()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, k := range r.keys {
+		if k.keyID == keyID {
+			return errors.New("key ID already registered")
+		}
+	}
+	r.keys = append(r.keys, rotationKey{
+		signer:     sig,
+		keyID:      keyID,
+		activeFrom: activeFrom,
+	})
+	sort.SliceStable(r.keys, func(i, j int) bool {
+		return r.keys[i].activeFrom.Before(r.keys[j].activeFrom)
+	})
+
+	// drop keys that can no longer be used for verification
+	verifiable := r.verificationKeys(r.now())
+	r.keys = append(r.keys[:0], verifiable...)
+	return nil
+}
+
+// Current returns signer of currently active key. ErrNoActiveKey is returned
+// if none of registered keys is active yet.
+func (r *RotatingSigner) Current() (Signer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if i := r.active(r.now()); i >= 0 {
+		return r.keys[i].signer, nil
+	}
+	return nil, ErrNoActiveKey
+}
+
+// active returns index of the key active at given time or -1.
+func (r *RotatingSigner) active(now time.Time) int {
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if !r.keys[i].activeFrom.After(now) {
+			return i
+		}
+	}
+	return -1
+}
+
+// verificationKeys returns next, active and retired keys that are still
+// within grace period.
+func (r *RotatingSigner) verificationKeys(now time.Time) []rotationKey {
+	active := r.active(now)
+	var keys []rotationKey
+	for i, k := range r.keys {
+		if i < active {
+			retiredAt := r.keys[i+1].activeFrom
+			if now.Sub(retiredAt) >= r.grace {
+				continue
+			}
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Algorithm returns algorithm of currently active key or empty string if
+// there is no active key.
+func (r *RotatingSigner) Algorithm() string {
+	sig, err := r.Current()
+	if err != nil {
+		return ""
+	}
+	return sig.Algorithm()
+}
+
+// Sign returns signature computed using currently active key.
+func (r *RotatingSigner) Sign(data []byte) ([]byte, error) {
+	sig, err := r.Current()
+	if err != nil {
+		return nil, err
+	}
+	return sig.Sign(data)
+}
+
+// SignContext is like Sign, but given context is used if active key
+// implements ContextSigner.
+func (r *RotatingSigner) SignContext(ctx context.Context, data []byte) ([]byte, error) {
+	sig, err := r.Current()
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := sig.(ContextSigner); ok {
+		return s.SignContext(ctx, data)
+	}
+	return sig.Sign(data)
+}
+
+// Verify returns nil if signature can be verified by any key from the
+// verification set.
+func (r *RotatingSigner) Verify(signature, data []byte) error {
+	r.mu.RLock()
+	keys := r.verificationKeys(r.now())
+	r.mu.RUnlock()
+
+	for _, k := range keys {
+		if err := k.signer.Verify(signature, data); err == nil {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// Lookup returns verifier for the key from verification set with given key
+// ID and algorithm.
+func (r *RotatingSigner) Lookup(alg, keyID string) (Verifier, error) {
+	r.mu.RLock()
+	keys := r.verificationKeys(r.now())
+	r.mu.RUnlock()
+
+	for _, k := range keys {
+		if k.keyID == keyID && k.signer.Algorithm() == alg {
+			return k.signer, nil
+		}
+	}
+	return nil, ErrInvalidSigner
+}
+
+// JWKS returns public keys of the verification set, serialized as JSON Web
+// Key Set. Keys that are not providing public key (symmetric algorithms) are
+// never published.
+func (r *RotatingSigner) JWKS() *JWKSet {
+	r.mu.RLock()
+	keys := r.verificationKeys(r.now())
+	r.mu.RUnlock()
+
+	set := &JWKSet{Keys: []JWK{}}
+	for _, k := range keys {
+		jwk, err := PublicJWK(k.signer)
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
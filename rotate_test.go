@@ -0,0 +1,79 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRotatingSigner(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	rot := NewRotatingSigner(time.Hour, clock)
+	if _, err := Encode(rot, map[string]string{"foo": "bar"}); err != ErrNoActiveKey {
+		t.Fatalf("want ErrNoActiveKey, got %v", err)
+	}
+
+	first := RSA256Signer(privRSA, "first")
+	second := HMAC256([]byte("top secret 1249812"), "second")
+	if err := rot.Add(first, now); err != nil {
+		t.Fatalf("cannot add first key: %s", err)
+	}
+	if err := rot.Add(second, now.Add(24*time.Hour)); err != nil {
+		t.Fatalf("cannot add second key: %s", err)
+	}
+	if err := rot.Add(HMAC256([]byte("x"), ""), now); err == nil {
+		t.Fatal("want error when adding key without ID")
+	}
+	if err := rot.Add(HMAC256([]byte("x"), "first"), now); err == nil {
+		t.Fatal("want error when adding duplicated key ID")
+	}
+
+	// next key is published, but not used for signing yet
+	if jwks := rot.JWKS(); len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != "first" {
+		t.Fatalf("want only first key published, got %+v", jwks)
+	}
+	oldToken, err := Encode(rot, map[string]string{"foo": "bar"})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+	assertKeyID(t, oldToken, "first")
+
+	// rotate
+	now = now.Add(24 * time.Hour)
+	newToken, err := Encode(rot, map[string]string{"foo": "bar"})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+	assertKeyID(t, newToken, "second")
+
+	var claims map[string]string
+	if err := DecodeClaims(newToken, rot, &claims); err != nil {
+		t.Fatalf("cannot decode new token: %s", err)
+	}
+	if err := DecodeClaims(oldToken, rot, &claims); err != nil {
+		t.Fatalf("cannot decode token within grace period: %s", err)
+	}
+
+	// grace period passed
+	now = now.Add(time.Hour)
+	if err := DecodeClaims(oldToken, rot, &claims); err != ErrInvalidSigner {
+		t.Fatalf("want ErrInvalidSigner, got %v", err)
+	}
+	if jwks := rot.JWKS(); len(jwks.Keys) != 0 {
+		t.Fatalf("want no key published, got %+v", jwks)
+	}
+}
+
+func assertKeyID(t *testing.T, token []byte, keyID string) {
+	t.Helper()
+	var header struct {
+		KeyID string `json:"kid"`
+	}
+	if err := DecodeHeader(token, &header); err != nil {
+		t.Fatalf("cannot decode header: %s", err)
+	}
+	if header.KeyID != keyID {
+		t.Fatalf("want %q key ID, got %q", keyID, header.KeyID)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
-	"fmt"
 )
 
 type rsaSigner struct {
@@ -24,16 +23,16 @@ func (s *rsaSigner) KeyID() string {
 	return s.keyID
 }
 
+func (s *rsaSigner) PublicKey() crypto.PublicKey {
+	return &s.key.PublicKey
+}
+
 func (s *rsaSigner) Sign(data []byte) ([]byte, error) {
 	if !s.hash.Available() {
 		return nil, ErrAlgorithmNotAvailable
 	}
 
-	hasher := s.hash.New()
-	if _, err := hasher.Write(data); err != nil {
-		return nil, fmt.Errorf("cannot hash: %s", err)
-	}
-	b := hasher.Sum(nil)
+	b := hashSum(s.hash, data)
 	return rsa.SignPKCS1v15(rand.Reader, s.key, s.hash, b)
 }
 
@@ -42,11 +41,7 @@ func (s *rsaSigner) Verify(signature, data []byte) error {
 		return ErrAlgorithmNotAvailable
 	}
 
-	hasher := s.hash.New()
-	if _, err := hasher.Write(data); err != nil {
-		return fmt.Errorf("cannot hash: %s", err)
-	}
-	b := hasher.Sum(nil)
+	b := hashSum(s.hash, data)
 	if err := rsa.VerifyPKCS1v15(&s.key.PublicKey, s.hash, b, signature); err != nil {
 		return ErrInvalidSignature
 	}
@@ -65,26 +60,40 @@ func (v *rsaVerifier) Algorithm() string {
 	return v.alg
 }
 
+func (v *rsaVerifier) PublicKey() crypto.PublicKey {
+	return v.key
+}
+
 func (v *rsaVerifier) Verify(signature, data []byte) error {
 	if !v.hash.Available() {
 		return ErrAlgorithmNotAvailable
 	}
 
-	hasher := v.hash.New()
-	if _, err := hasher.Write(data); err != nil {
-		return fmt.Errorf("cannot hash: %s", err)
-	}
-	b := hasher.Sum(nil)
+	b := hashSum(v.hash, data)
 	if err := rsa.VerifyPKCS1v15(v.key, v.hash, b, signature); err != nil {
 		return ErrInvalidSignature
 	}
 	return nil
 }
 
+// NewRSA256Signer is like RSA256Signer, but returns ErrWeakKey if key is
+// shorter than MinRSAKeySize, unless AllowWeakKeys option is used.
+func NewRSA256Signer(key *rsa.PrivateKey, keyID string, opts ...KeyOption) (Signer, error) {
+	if key == nil {
+		return nil, ErrUnsupportedKey
+	}
+	if err := checkRSAKey(&key.PublicKey, opts); err != nil {
+		return nil, err
+	}
+	return RSA256Signer(key, keyID), nil
+}
+
 // RSA256Signer returns signer using asymmetric RSA algorithm to sign data.
 //
 // keyID is optional (can be empty) argument that is helpful when using several
 // keys to sign data, to determine which key to use during verification.
+//
+// Key strength is not validated, see NewRSA256Signer.
 func RSA256Signer(key *rsa.PrivateKey, keyID string) Signer {
 	return &rsaSigner{
 		alg:   "RS256",
@@ -94,8 +103,17 @@ func RSA256Signer(key *rsa.PrivateKey, keyID string) Signer {
 	}
 }
 
+// NewRSA256Verifier is like RSA256Verifier, but returns ErrWeakKey if key is
+// shorter than MinRSAKeySize, unless AllowWeakKeys option is used.
+func NewRSA256Verifier(key *rsa.PublicKey, opts ...KeyOption) (Verifier, error) {
+	if err := checkRSAKey(key, opts); err != nil {
+		return nil, err
+	}
+	return RSA256Verifier(key), nil
+}
+
 // RSA256Verifier returns verifier using asymmetric RSA algorithm to verify
-// data signature.
+// data signature. Key strength is not validated, see NewRSA256Verifier.
 func RSA256Verifier(key *rsa.PublicKey) Verifier {
 	return &rsaVerifier{
 		alg:  "RS256",
@@ -104,10 +122,24 @@ func RSA256Verifier(key *rsa.PublicKey) Verifier {
 	}
 }
 
+// NewRSA384Signer is like RSA384Signer, but returns ErrWeakKey if key is
+// shorter than MinRSAKeySize, unless AllowWeakKeys option is used.
+func NewRSA384Signer(key *rsa.PrivateKey, keyID string, opts ...KeyOption) (Signer, error) {
+	if key == nil {
+		return nil, ErrUnsupportedKey
+	}
+	if err := checkRSAKey(&key.PublicKey, opts); err != nil {
+		return nil, err
+	}
+	return RSA384Signer(key, keyID), nil
+}
+
 // RSA384Signer returns signer using asymmetric RSA algorithm to sign data.
 //
 // keyID is optional (can be empty) argument that is helpful when using several
 // keys to sign data, to determine which key to use during verification.
+//
+// Key strength is not validated, see NewRSA384Signer.
 func RSA384Signer(key *rsa.PrivateKey, keyID string) Signer {
 	return &rsaSigner{
 		alg:   "RS384",
@@ -117,8 +149,17 @@ func RSA384Signer(key *rsa.PrivateKey, keyID string) Signer {
 	}
 }
 
+// NewRSA384Verifier is like RSA384Verifier, but returns ErrWeakKey if key is
+// shorter than MinRSAKeySize, unless AllowWeakKeys option is used.
+func NewRSA384Verifier(key *rsa.PublicKey, opts ...KeyOption) (Verifier, error) {
+	if err := checkRSAKey(key, opts); err != nil {
+		return nil, err
+	}
+	return RSA384Verifier(key), nil
+}
+
 // RSA384Verifier returns verifier using asymmetric RSA algorithm to verify
-// data signature.
+// data signature. Key strength is not validated, see NewRSA384Verifier.
 func RSA384Verifier(key *rsa.PublicKey) Verifier {
 	return &rsaVerifier{
 		alg:  "RS384",
@@ -127,10 +168,24 @@ func RSA384Verifier(key *rsa.PublicKey) Verifier {
 	}
 }
 
+// NewRSA512Signer is like RSA512Signer, but returns ErrWeakKey if key is
+// shorter than MinRSAKeySize, unless AllowWeakKeys option is used.
+func NewRSA512Signer(key *rsa.PrivateKey, keyID string, opts ...KeyOption) (Signer, error) {
+	if key == nil {
+		return nil, ErrUnsupportedKey
+	}
+	if err := checkRSAKey(&key.PublicKey, opts); err != nil {
+		return nil, err
+	}
+	return RSA512Signer(key, keyID), nil
+}
+
 // RSA512Signer returns signer using asymmetric RSA algorithm to sign data.
 //
 // keyID is optional (can be empty) argument that is helpful when using several
 // keys to sign data, to determine which key to use during verification.
+//
+// Key strength is not validated, see NewRSA512Signer.
 func RSA512Signer(key *rsa.PrivateKey, keyID string) Signer {
 	return &rsaSigner{
 		alg:   "RS512",
@@ -140,8 +195,17 @@ func RSA512Signer(key *rsa.PrivateKey, keyID string) Signer {
 	}
 }
 
+// NewRSA512Verifier is like RSA512Verifier, but returns ErrWeakKey if key is
+// shorter than MinRSAKeySize, unless AllowWeakKeys option is used.
+func NewRSA512Verifier(key *rsa.PublicKey, opts ...KeyOption) (Verifier, error) {
+	if err := checkRSAKey(key, opts); err != nil {
+		return nil, err
+	}
+	return RSA512Verifier(key), nil
+}
+
 // RSA512Verifier returns verifier using asymmetric RSA algorithm to verify
-// data signature.
+// data signature. Key strength is not validated, see NewRSA512Verifier.
 func RSA512Verifier(key *rsa.PublicKey) Verifier {
 	return &rsaVerifier{
 		alg:  "RS512",
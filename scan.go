@@ -0,0 +1,377 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+)
+
+// scanObject walks members of the top level JSON object, calling fn with
+// unquoted member name and raw member value. Nested values are
+// validated, but not decoded, so that no memory is allocated.
+func scanObject(data []byte, fn func(name, value []byte) error) error {
+	return scanObjectLimited(data, 0, false, fn)
+}
+
+// scanObjectLimited is like scanObject, but returns ErrNestingTooDeep if
+// values are nested deeper than maxDepth, counting the top level object.
+// Zero maxDepth means no limit. If unique is true, ErrDuplicateMember is
+// returned if any top level member name is repeated. Fn is optional (can be
+// nil), in which case data is only validated.
+func scanObjectLimited(data []byte, maxDepth int, unique bool, fn func(name, value []byte) error) error {
+	depth := maxDepth - 1
+	if maxDepth <= 0 {
+		depth = -1
+	}
+	var names memberNames
+
+	i := skipSpace(data, 0)
+	if i == len(data) || data[i] != '{' {
+		return errSyntax
+	}
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == '}' {
+		return expectEnd(data, i+1)
+	}
+
+	for {
+		if i == len(data) || data[i] != '"' {
+			return errSyntax
+		}
+		end, err := skipString(data, i)
+		if err != nil {
+			return err
+		}
+		name := data[i+1 : end-1]
+		if bytes.IndexByte(name, '\\') >= 0 {
+			if name, err = jsonString(data[i:end]); err != nil {
+				return err
+			}
+		}
+		if unique && !names.add(name) {
+			return ErrDuplicateMember
+		}
+
+		i = skipSpace(data, end)
+		if i == len(data) || data[i] != ':' {
+			return errSyntax
+		}
+		start := skipSpace(data, i+1)
+		end, err = skipValue(data, start, depth)
+		if err != nil {
+			return err
+		}
+		if fn != nil {
+			if err := fn(name, data[start:end]); err != nil {
+				return err
+			}
+		}
+
+		i = skipSpace(data, end)
+		if i == len(data) {
+			return errSyntax
+		}
+		switch data[i] {
+		case ',':
+			i = skipSpace(data, i+1)
+		case '}':
+			return expectEnd(data, i+1)
+		default:
+			return errSyntax
+		}
+	}
+}
+
+var errSyntax = errors.New("invalid JSON")
+
+// memberNames tracks object member names. Small number of names is kept in a
+// fixed array, so that common objects are checked without allocation.
+type memberNames struct {
+	small [16][]byte
+	n     int
+	large map[string]struct{}
+}
+
+// add returns false if given name was already added.
+func (m *memberNames) add(name []byte) bool {
+	if m.large != nil {
+		if _, ok := m.large[string(name)]; ok {
+			return false
+		}
+		m.large[string(name)] = struct{}{}
+		return true
+	}
+	for _, seen := range m.small[:m.n] {
+		if bytes.Equal(seen, name) {
+			return false
+		}
+	}
+	if m.n < len(m.small) {
+		m.small[m.n] = name
+		m.n++
+		return true
+	}
+	m.large = make(map[string]struct{}, 2*len(m.small))
+	for _, seen := range m.small[:m.n] {
+		m.large[string(seen)] = struct{}{}
+	}
+	m.large[string(name)] = struct{}{}
+	return true
+}
+
+func expectEnd(data []byte, i int) error {
+	if skipSpace(data, i) != len(data) {
+		return errSyntax
+	}
+	return nil
+}
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\r', '\n':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// skipValue returns index of the first byte after JSON value starting at
+// given position. Depth is the number of nested objects or arrays that value
+// can open, negative for no limit.
+func skipValue(data []byte, i int, depth int) (int, error) {
+	if i >= len(data) {
+		return 0, errSyntax
+	}
+	switch c := data[i]; {
+	case c == '"':
+		return skipString(data, i)
+	case c == '{':
+		if depth == 0 {
+			return 0, ErrNestingTooDeep
+		}
+		i = skipSpace(data, i+1)
+		if i < len(data) && data[i] == '}' {
+			return i + 1, nil
+		}
+		for {
+			if i == len(data) || data[i] != '"' {
+				return 0, errSyntax
+			}
+			end, err := skipString(data, i)
+			if err != nil {
+				return 0, err
+			}
+			i = skipSpace(data, end)
+			if i == len(data) || data[i] != ':' {
+				return 0, errSyntax
+			}
+			if i, err = skipValue(data, skipSpace(data, i+1), depth-1); err != nil {
+				return 0, err
+			}
+			i = skipSpace(data, i)
+			if i == len(data) {
+				return 0, errSyntax
+			}
+			switch data[i] {
+			case ',':
+				i = skipSpace(data, i+1)
+			case '}':
+				return i + 1, nil
+			default:
+				return 0, errSyntax
+			}
+		}
+	case c == '[':
+		if depth == 0 {
+			return 0, ErrNestingTooDeep
+		}
+		i = skipSpace(data, i+1)
+		if i < len(data) && data[i] == ']' {
+			return i + 1, nil
+		}
+		for {
+			var err error
+			if i, err = skipValue(data, i, depth-1); err != nil {
+				return 0, err
+			}
+			i = skipSpace(data, i)
+			if i == len(data) {
+				return 0, errSyntax
+			}
+			switch data[i] {
+			case ',':
+				i = skipSpace(data, i+1)
+			case ']':
+				return i + 1, nil
+			default:
+				return 0, errSyntax
+			}
+		}
+	case c == 't':
+		return skipLiteral(data, i, "true")
+	case c == 'f':
+		return skipLiteral(data, i, "false")
+	case c == 'n':
+		return skipLiteral(data, i, "null")
+	case c == '-' || (c >= '0' && c <= '9'):
+		return skipNumber(data, i)
+	default:
+		return 0, errSyntax
+	}
+}
+
+func skipLiteral(data []byte, i int, literal string) (int, error) {
+	if !bytes.HasPrefix(data[i:], []byte(literal)) {
+		return 0, errSyntax
+	}
+	return i + len(literal), nil
+}
+
+// skipString returns index of the first byte after JSON string starting at
+// given position.
+func skipString(data []byte, i int) (int, error) {
+	for i++; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '"':
+			return i + 1, nil
+		case c == '\\':
+			i++
+			if i == len(data) {
+				return 0, errSyntax
+			}
+			switch data[i] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			case 'u':
+				if i+4 >= len(data) {
+					return 0, errSyntax
+				}
+				for _, h := range data[i+1 : i+5] {
+					if !isHex(h) {
+						return 0, errSyntax
+					}
+				}
+				i += 4
+			default:
+				return 0, errSyntax
+			}
+		case c < 0x20:
+			return 0, errSyntax
+		}
+	}
+	return 0, errSyntax
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// skipNumber returns index of the first byte after JSON number starting at
+// given position.
+func skipNumber(data []byte, i int) (int, error) {
+	if data[i] == '-' {
+		i++
+	}
+	switch {
+	case i == len(data):
+		return 0, errSyntax
+	case data[i] == '0':
+		i++
+	case data[i] >= '1' && data[i] <= '9':
+		i = skipDigits(data, i)
+	default:
+		return 0, errSyntax
+	}
+	if i < len(data) && data[i] == '.' {
+		start := i + 1
+		if i = skipDigits(data, start); i == start {
+			return 0, errSyntax
+		}
+	}
+	if i < len(data) && (data[i] == 'e' || data[i] == 'E') {
+		i++
+		if i < len(data) && (data[i] == '+' || data[i] == '-') {
+			i++
+		}
+		start := i
+		if i = skipDigits(data, start); i == start {
+			return 0, errSyntax
+		}
+	}
+	return i, nil
+}
+
+func skipDigits(data []byte, i int) int {
+	for i < len(data) && data[i] >= '0' && data[i] <= '9' {
+		i++
+	}
+	return i
+}
+
+// jsonString returns content of raw JSON string value. Memory is allocated
+// only if string contains escape sequences. Null is returned as nil.
+func jsonString(raw []byte) ([]byte, error) {
+	if string(raw) == "null" {
+		return nil, nil
+	}
+	if len(raw) < 2 || raw[0] != '"' {
+		return nil, errors.New("not a string")
+	}
+	if s := raw[1 : len(raw)-1]; bytes.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// numericDate returns raw JSON NumericDate value as defined in RFC 7519,
+// section 2, truncated to full seconds. Null is returned as zero.
+func numericDate(raw []byte) (int64, error) {
+	if string(raw) == "null" {
+		return 0, nil
+	}
+
+	if n, ok := parseInteger(raw); ok {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(string(raw), 64)
+	if err != nil {
+		return 0, errors.New("not a number")
+	}
+	// conversion of values outside of int64 range is implementation
+	// specific, so that they must be rejected to keep comparisons correct
+	if math.IsNaN(f) || f >= 1<<63 || f < -(1<<63) {
+		return 0, errors.New("number out of range")
+	}
+	return int64(f), nil
+}
+
+// parseInteger is a fast path for parsing integer values without memory
+// allocation. Too long values are not handled.
+func parseInteger(raw []byte) (int64, bool) {
+	digits := raw
+	if len(digits) > 0 && digits[0] == '-' {
+		digits = digits[1:]
+	}
+	if len(digits) == 0 || len(digits) > 18 {
+		return 0, false
+	}
+	var n int64
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int64(c-'0')
+	}
+	if raw[0] == '-' {
+		n = -n
+	}
+	return n, true
+}
@@ -0,0 +1,132 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanObject(t *testing.T) {
+	cases := map[string]struct {
+		data    string
+		want    map[string]string
+		wantErr bool
+	}{
+		"empty": {
+			data: ` { } `,
+			want: map[string]string{},
+		},
+		"all-types": {
+			data: `{"a": "x\"y", "b": -1.5e+3, "c": [1, {"d": null}], "e": true, "f": false, "g": {}}`,
+			want: map[string]string{
+				"a": `"x\"y"`,
+				"b": `-1.5e+3`,
+				"c": `[1, {"d": null}]`,
+				"e": `true`,
+				"f": `false`,
+				"g": `{}`,
+			},
+		},
+		"escaped-name": {
+			data: `{"e\u0078p": 1}`,
+			want: map[string]string{"exp": "1"},
+		},
+		"not-object":        {data: `[1]`, wantErr: true},
+		"trailing-data":     {data: `{} {}`, wantErr: true},
+		"trailing-comma":    {data: `{"a": 1,}`, wantErr: true},
+		"unterminated":      {data: `{"a": "x`, wantErr: true},
+		"invalid-escape":    {data: `{"a": "\x"}`, wantErr: true},
+		"invalid-number":    {data: `{"a": 01}`, wantErr: true},
+		"invalid-literal":   {data: `{"a": nul}`, wantErr: true},
+		"control-character": {data: "{\"a\": \"\x01\"}", wantErr: true},
+	}
+
+	for tname, tc := range cases {
+		got := make(map[string]string)
+		err := scanObject([]byte(tc.data), func(name, value []byte) error {
+			got[string(name)] = string(value)
+			return nil
+		})
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: want error", tname)
+			}
+			if json.Valid([]byte(tc.data)) && tc.data[0] == '{' {
+				t.Errorf("%s: test data must be invalid JSON object", tname)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tname, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: want %v, got %v", tname, tc.want, got)
+		}
+	}
+}
+
+func TestScanObjectLimited(t *testing.T) {
+	var many []string
+	for i := 0; i < 40; i++ {
+		many = append(many, fmt.Sprintf(`"m%d": %d`, i, i))
+	}
+
+	cases := map[string]struct {
+		data     string
+		maxDepth int
+		unique   bool
+		wantErr  error
+	}{
+		"nested-within-limit": {data: `{"a": [[1]], "b": {"c": {}}}`, maxDepth: 3},
+		"nested-object":       {data: `{"a": {"b": {"c": 1}}}`, maxDepth: 2, wantErr: ErrNestingTooDeep},
+		"nested-array":        {data: `{"a": [[[1]]]}`, maxDepth: 3, wantErr: ErrNestingTooDeep},
+		"no-depth-limit":      {data: `{"a": [[[[[[1]]]]]]}`},
+		"duplicate":           {data: `{"a": 1, "b": 2, "a": 3}`, unique: true, wantErr: ErrDuplicateMember},
+		"duplicate-escaped":   {data: `{"exp": 1, "e\u0078p": 2}`, unique: true, wantErr: ErrDuplicateMember},
+		"duplicate-allowed":   {data: `{"a": 1, "a": 2}`},
+		"nested-duplicate":    {data: `{"a": {"b": 1, "b": 2}}`, unique: true},
+		"many-unique":         {data: "{" + strings.Join(many, ",") + "}", unique: true},
+		"many-with-duplicate": {data: "{" + strings.Join(many, ",") + `, "m35": 1}`, unique: true, wantErr: ErrDuplicateMember},
+	}
+
+	for tname, tc := range cases {
+		err := scanObjectLimited([]byte(tc.data), tc.maxDepth, tc.unique, nil)
+		if err != tc.wantErr {
+			t.Errorf("%s: want %v, got %v", tname, tc.wantErr, err)
+		}
+	}
+}
+
+func TestNumericDate(t *testing.T) {
+	cases := map[string]struct {
+		raw     string
+		want    int64
+		wantErr bool
+	}{
+		"integer":   {raw: "1516239022", want: 1516239022},
+		"negative":  {raw: "-5", want: -5},
+		"fraction":  {raw: "1516239022.75", want: 1516239022},
+		"exponent":  {raw: "1.5e3", want: 1500},
+		"null":      {raw: "null", want: 0},
+		"string":    {raw: `"1516239022"`, wantErr: true},
+		"too-large": {raw: "1e400", wantErr: true},
+		"overflow":  {raw: "1e19", wantErr: true},
+		"underflow": {raw: "-1e19", wantErr: true},
+		"long":      {raw: "99999999999999999999", wantErr: true},
+		"min-int":   {raw: "-9223372036854775808", want: -1 << 63},
+	}
+
+	for tname, tc := range cases {
+		got, err := numericDate([]byte(tc.raw))
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: want error %v, got %v", tname, tc.wantErr, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: want %d, got %d", tname, tc.want, got)
+		}
+	}
+}
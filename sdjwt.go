@@ -0,0 +1,346 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// KeyBindingType is the token type ("typ") header value of SD-JWT key
+// binding JWT.
+const KeyBindingType = "kb+jwt"
+
+// Disclosure is a single selectively disclosable claim of SD-JWT.
+type Disclosure struct {
+	Salt  string
+	Name  string
+	Value interface{}
+
+	// Encoded is base64 encoded disclosure, as present in SD-JWT.
+	Encoded string
+}
+
+// digest returns SHA-256 digest of the encoded disclosure, as present in the
+// "_sd" claim.
+func (d *Disclosure) digest() string {
+	sum := sha256.Sum256([]byte(d.Encoded))
+	b, _ := encode(sum[:])
+	return string(b)
+}
+
+// ErrInvalidDisclosure is returned when SD-JWT disclosure cannot be decoded
+// or is not referenced by the signed token.
+var ErrInvalidDisclosure = errors.New("invalid disclosure")
+
+// IssueSDJWT returns SD-JWT in which all claims listed as disclosable are
+// replaced by digests of their disclosures, so that the holder can later
+// decide which of them to reveal. Only top level claims can be selectively
+// disclosable.
+//
+// holder is optional (can be nil) public key of the holder. If provided, it
+// is included in "cnf" claim and verifiers can require key binding.
+func IssueSDJWT(sig Signer, claims map[string]interface{}, disclosable []string, holder *JWK, opts ...EncodeOption) ([]byte, error) {
+	payload := make(map[string]interface{}, len(claims)+2)
+	for name, value := range claims {
+		payload[name] = value
+	}
+
+	var disclosures []Disclosure
+	var digests []string
+	for _, name := range disclosable {
+		value, ok := payload[name]
+		if !ok {
+			return nil, fmt.Errorf("disclosable claim %q not present", name)
+		}
+		delete(payload, name)
+
+		d, err := newDisclosure(name, value)
+		if err != nil {
+			return nil, err
+		}
+		disclosures = append(disclosures, d)
+		digests = append(digests, d.digest())
+	}
+	if len(digests) != 0 {
+		// digests are sorted, so that their order does not reveal the
+		// original claims order
+		sort.Strings(digests)
+		payload["_sd"] = digests
+	}
+	payload["_sd_alg"] = "sha-256"
+	if holder != nil {
+		payload["cnf"] = &Confirmation{JWK: holder}
+	}
+
+	token, err := Encode(sig, payload, opts...)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range disclosures {
+		token = append(token, '~')
+		token = append(token, d.Encoded...)
+	}
+	return append(token, '~'), nil
+}
+
+func newDisclosure(name string, value interface{}) (Disclosure, error) {
+	salt, err := randomID()
+	if err != nil {
+		return Disclosure{}, err
+	}
+	b, err := json.Marshal([]interface{}{salt, name, value})
+	if err != nil {
+		return Disclosure{}, fmt.Errorf("cannot encode disclosure: %s", err)
+	}
+	encoded, _ := encode(b)
+	return Disclosure{
+		Salt:    salt,
+		Name:    name,
+		Value:   value,
+		Encoded: string(encoded),
+	}, nil
+}
+
+// parseDisclosure decodes base64 encoded disclosure of the object property.
+func parseDisclosure(encoded []byte) (Disclosure, error) {
+	b, err := decodeSegment(encoded, false)
+	if err != nil {
+		return Disclosure{}, ErrInvalidDisclosure
+	}
+	var parts []interface{}
+	if err := json.Unmarshal(b, &parts); err != nil || len(parts) != 3 {
+		return Disclosure{}, ErrInvalidDisclosure
+	}
+	salt, ok := parts[0].(string)
+	if !ok {
+		return Disclosure{}, ErrInvalidDisclosure
+	}
+	name, ok := parts[1].(string)
+	if !ok || name == "_sd" || name == "..." {
+		return Disclosure{}, ErrInvalidDisclosure
+	}
+	return Disclosure{
+		Salt:    salt,
+		Name:    name,
+		Value:   parts[2],
+		Encoded: string(encoded),
+	}, nil
+}
+
+// splitSDJWT split SD-JWT into issuer signed JWT, disclosures and optional
+// key binding JWT.
+func splitSDJWT(sdjwt []byte) (token []byte, disclosures [][]byte, kb []byte, err error) {
+	parts := bytes.Split(sdjwt, []byte{'~'})
+	if len(parts) < 2 || len(parts[0]) == 0 {
+		return nil, nil, nil, ErrMalformedToken
+	}
+	disclosures = parts[1 : len(parts)-1]
+	for _, d := range disclosures {
+		if len(d) == 0 {
+			return nil, nil, nil, ErrMalformedToken
+		}
+	}
+	return parts[0], disclosures, parts[len(parts)-1], nil
+}
+
+// PresentSDJWT returns SD-JWT presentation revealing only claims with given
+// names. Disclosures of all other claims are removed.
+//
+// kb is optional (can be nil) signer holding holder private key. If given,
+// key binding JWT for given audience and nonce is appended.
+func PresentSDJWT(sdjwt []byte, reveal []string, kb Signer, audience, nonce string) ([]byte, error) {
+	token, disclosures, _, err := splitSDJWT(sdjwt)
+	if err != nil {
+		return nil, err
+	}
+
+	presentation := append([]byte{}, token...)
+	presentation = append(presentation, '~')
+	for _, encoded := range disclosures {
+		d, err := parseDisclosure(encoded)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range reveal {
+			if d.Name == name {
+				presentation = append(presentation, encoded...)
+				presentation = append(presentation, '~')
+				break
+			}
+		}
+	}
+	if kb == nil {
+		return presentation, nil
+	}
+
+	kbToken, err := Encode(kb, keyBindingClaims{
+		IssuedAt: time.Now().Unix(),
+		Audience: audience,
+		Nonce:    nonce,
+		SDHash:   sdHash(presentation),
+	}, EncodeWithType(KeyBindingType))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create key binding: %s", err)
+	}
+	return append(presentation, kbToken...), nil
+}
+
+type keyBindingClaims struct {
+	IssuedAt int64  `json:"iat"`
+	Audience string `json:"aud"`
+	Nonce    string `json:"nonce"`
+	SDHash   string `json:"sd_hash"`
+}
+
+// sdHash returns "sd_hash" value for given presentation without key binding
+// JWT.
+func sdHash(presentation []byte) string {
+	sum := sha256.Sum256(presentation)
+	b, _ := encode(sum[:])
+	return string(b)
+}
+
+// SDJWTVerifier validates SD-JWT presentations and reconstructs disclosed
+// claims.
+type SDJWTVerifier struct {
+	// Verifier checks issuer signature.
+	Verifier Verifier
+
+	// RequireKeyBinding makes verification fail for presentations
+	// without key binding JWT.
+	RequireKeyBinding bool
+
+	// Audience and Nonce are the values that key binding JWT must be
+	// created for.
+	Audience string
+	Nonce    string
+
+	// MaxAge is the maximum accepted age of the key binding JWT. Defaults
+	// to five minutes.
+	MaxAge time.Duration
+
+	// Now is optional (can be nil) function returning current time. It
+	// is useful mostly for testing.
+	Now func() time.Time
+}
+
+// Verify validates SD-JWT presentation and returns claims signed by issuer,
+// together with all disclosed claims. Digests of undisclosed claims are
+// removed.
+func (sv *SDJWTVerifier) Verify(presentation []byte) (map[string]interface{}, error) {
+	now := time.Now
+	if sv.Now != nil {
+		now = sv.Now
+	}
+
+	token, disclosures, kb, err := splitSDJWT(presentation)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := DecodeClaims(token, sv.Verifier, &claims, WithClock(now)); err != nil {
+		return nil, err
+	}
+	if alg, ok := claims["_sd_alg"]; ok && alg != "sha-256" {
+		return nil, &ClaimError{Claim: "_sd_alg", Err: ErrAlgorithmNotAvailable}
+	}
+
+	digests := make(map[string]bool)
+	if raw, ok := claims["_sd"]; ok {
+		list, ok := raw.([]interface{})
+		if !ok {
+			return nil, &ClaimError{Claim: "_sd", Err: errors.New("not an array")}
+		}
+		for _, d := range list {
+			s, ok := d.(string)
+			if !ok {
+				return nil, &ClaimError{Claim: "_sd", Err: errors.New("not a string")}
+			}
+			digests[s] = false
+		}
+	}
+	delete(claims, "_sd")
+	delete(claims, "_sd_alg")
+
+	for _, encoded := range disclosures {
+		d, err := parseDisclosure(encoded)
+		if err != nil {
+			return nil, err
+		}
+		// every disclosure must be referenced exactly once and must not
+		// override claims signed in plain text
+		used, ok := digests[d.digest()]
+		if !ok || used {
+			return nil, ErrInvalidDisclosure
+		}
+		digests[d.digest()] = true
+		if _, ok := claims[d.Name]; ok {
+			return nil, ErrInvalidDisclosure
+		}
+		claims[d.Name] = d.Value
+	}
+
+	if len(kb) == 0 {
+		if sv.RequireKeyBinding {
+			return nil, errors.New("key binding required")
+		}
+		return claims, nil
+	}
+	if err := sv.verifyKeyBinding(claims, presentation[:len(presentation)-len(kb)], kb, now); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// verifyKeyBinding validates key binding JWT using holder key declared in
+// "cnf" claim.
+func (sv *SDJWTVerifier) verifyKeyBinding(claims map[string]interface{}, presentation, kb []byte, now func() time.Time) error {
+	cnf, ok := claims["cnf"].(map[string]interface{})
+	if !ok {
+		return &ClaimError{Claim: "cnf", Err: ErrMissingClaim}
+	}
+	jwk, err := embeddedJWK(cnf)
+	if err != nil {
+		return &ClaimError{Claim: "cnf", Err: err}
+	}
+	tok, err := ParseUnverified(kb)
+	if err != nil {
+		return err
+	}
+	v, err := jwk.Verifier(tok.Algorithm())
+	if err != nil {
+		return err
+	}
+
+	maxAge := sv.MaxAge
+	if maxAge == 0 {
+		maxAge = 5 * time.Minute
+	}
+	var kbClaims keyBindingClaims
+	err = DecodeClaims(kb, v, &kbClaims,
+		WithClock(now),
+		WithType(KeyBindingType),
+		WithRequiredClaims("iat", "aud", "nonce", "sd_hash"))
+	if err != nil {
+		return err
+	}
+	if d := now().Sub(time.Unix(kbClaims.IssuedAt, 0)); d > maxAge || d < -maxAge {
+		return &ClaimError{Claim: "iat", Err: errors.New("key binding issued outside of accepted window")}
+	}
+	if kbClaims.Audience != sv.Audience {
+		return &ClaimError{Claim: "aud", Err: errors.New("unexpected audience")}
+	}
+	if subtle.ConstantTimeCompare([]byte(kbClaims.Nonce), []byte(sv.Nonce)) != 1 {
+		return &ClaimError{Claim: "nonce", Err: errors.New("nonce mismatch")}
+	}
+	if kbClaims.SDHash != sdHash(presentation) {
+		return &ClaimError{Claim: "sd_hash", Err: errors.New("presentation hash mismatch")}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package jwt
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSDJWT(t *testing.T) {
+	issuer := HMAC256([]byte("issuer secret 1120931"), "issuer-key")
+	holder := RSA256Signer(privRSA2048, "")
+	holderJWK, err := PublicJWK(holder)
+	if err != nil {
+		t.Fatalf("cannot create holder JWK: %s", err)
+	}
+
+	sdjwt, err := IssueSDJWT(issuer, map[string]interface{}{
+		"iss":         "https://issuer.example.com",
+		"given_name":  "John",
+		"family_name": "Doe",
+		"birthdate":   "1940-01-01",
+	}, []string{"given_name", "family_name", "birthdate"}, &holderJWK)
+	if err != nil {
+		t.Fatalf("cannot issue: %s", err)
+	}
+
+	presentation, err := PresentSDJWT(sdjwt, []string{"given_name"}, holder, "https://verifier.example.com", "n-1234")
+	if err != nil {
+		t.Fatalf("cannot present: %s", err)
+	}
+
+	sv := &SDJWTVerifier{
+		Verifier:          issuer,
+		RequireKeyBinding: true,
+		Audience:          "https://verifier.example.com",
+		Nonce:             "n-1234",
+	}
+	claims, err := sv.Verify(presentation)
+	if err != nil {
+		t.Fatalf("cannot verify: %s", err)
+	}
+	if claims["given_name"] != "John" || claims["iss"] != "https://issuer.example.com" {
+		t.Fatalf("unexpected claims: %v", claims)
+	}
+	for _, name := range []string{"family_name", "birthdate", "_sd", "_sd_alg"} {
+		if _, ok := claims[name]; ok {
+			t.Errorf("%q claim must not be present", name)
+		}
+	}
+
+	// full SD-JWT without key binding
+	if _, err := sv.Verify(sdjwt); err == nil {
+		t.Fatal("want error for missing key binding")
+	}
+	sv.RequireKeyBinding = false
+	if claims, err := sv.Verify(sdjwt); err != nil || claims["birthdate"] != "1940-01-01" {
+		t.Fatalf("cannot verify all disclosures: %v, %s", claims, err)
+	}
+
+	// presentation with key binding for other verifier
+	sv.Audience = "https://other.example.com"
+	if _, err := sv.Verify(presentation); err == nil {
+		t.Fatal("want error for key binding created for other audience")
+	}
+	sv.Audience = "https://verifier.example.com"
+
+	// disclosure not created by issuer
+	forged, err := newDisclosure("admin", true)
+	if err != nil {
+		t.Fatalf("cannot create disclosure: %s", err)
+	}
+	i := bytes.IndexByte(presentation, '~')
+	tampered := append(append([]byte{}, presentation[:i+1]...), forged.Encoded+"~"...)
+	if _, err := sv.Verify(tampered); err != ErrInvalidDisclosure {
+		t.Fatalf("want ErrInvalidDisclosure, got %v", err)
+	}
+
+	// key binding too old
+	sv.Now = func() time.Time { return time.Now().Add(time.Hour) }
+	if _, err := sv.Verify(presentation); err == nil {
+		t.Fatal("want error for old key binding")
+	}
+}
@@ -0,0 +1,201 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SecurityEventType is the token type ("typ") header value of Security Event
+// Tokens as defined in RFC 8417, section 2.3.
+const SecurityEventType = "secevent+jwt"
+
+// SecurityEventClaims holds claims of Security Event Token as defined in RFC
+// 8417, section 2.2.
+type SecurityEventClaims struct {
+	Issuer        string   `json:"iss"`
+	Audience      Audience `json:"aud,omitempty"`
+	IssuedAt      int64    `json:"iat"`
+	TokenID       string   `json:"jti"`
+	Subject       string   `json:"sub,omitempty"`
+	TransactionID string   `json:"txn,omitempty"`
+	TimeOfEvent   int64    `json:"toe,omitempty"`
+
+	// Events maps event type URI to event specific payload.
+	Events map[string]json.RawMessage `json:"events"`
+}
+
+// EncodeSecurityEvent return events serialized as signed Security Event
+// Token with "secevent+jwt" type. Events map event type URI to event
+// specific payload, that must serialize into JSON object.
+func EncodeSecurityEvent(sig Signer, issuer string, audience Audience, events map[string]interface{}) ([]byte, error) {
+	if len(events) == 0 {
+		return nil, errors.New("at least one event is required")
+	}
+	tokenID, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := SecurityEventClaims{
+		Issuer:   issuer,
+		Audience: audience,
+		IssuedAt: time.Now().Unix(),
+		TokenID:  tokenID,
+		Events:   make(map[string]json.RawMessage, len(events)),
+	}
+	for uri, payload := range events {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode %q event: %s", uri, err)
+		}
+		claims.Events[uri] = b
+	}
+	return Encode(sig, &claims, EncodeWithType(SecurityEventType))
+}
+
+// DefaultSecurityEventMaxAge is the maximum age of Security Event Tokens
+// accepted by SecurityEventReceiver with zero MaxAge.
+const DefaultSecurityEventMaxAge = 24 * time.Hour
+
+// SecurityEventHandler is a function that processes single event of the
+// received Security Event Token.
+type SecurityEventHandler func(event json.RawMessage, set *SecurityEventClaims) error
+
+// SecurityEventReceiver validates Security Event Tokens and dispatches their
+// events to handlers registered for event type URI.
+type SecurityEventReceiver struct {
+	// Verifier checks token signature.
+	Verifier Verifier
+
+	// Issuer is the expected token issuer.
+	Issuer string
+
+	// Audience, if not empty, must be present in token audience.
+	Audience string
+
+	// Store is optional ReplayStore, used to reject tokens delivered more
+	// than once. Security Event Tokens usually do not expire, so their
+	// IDs are passed to the store with expiration time computed as issue
+	// time plus MaxAge.
+	Store ReplayStore
+
+	// MaxAge is the maximum accepted age of the token, as given by its
+	// "iat" claim. Older tokens are rejected, so that their IDs need to be
+	// kept by Store only for that long. DefaultSecurityEventMaxAge is used
+	// if zero.
+	MaxAge time.Duration
+
+	// ClockSkew is the maximum accepted difference between issuer and
+	// receiver clocks. Tokens issued further in the future are rejected.
+	// Defaults to one minute.
+	ClockSkew time.Duration
+
+	// Now is optional (can be nil) function returning current time. It
+	// is useful mostly for testing.
+	Now func() time.Time
+
+	mu       sync.RWMutex
+	handlers map[string]SecurityEventHandler
+}
+
+// Handle registers handler for events of given type URI.
+func (r *SecurityEventReceiver) Handle(eventType string, h SecurityEventHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.handlers == nil {
+		r.handlers = make(map[string]SecurityEventHandler)
+	}
+	r.handlers[eventType] = h
+}
+
+// Receive validates Security Event Token and calls handler of every
+// contained event, in event type URI order. Events without registered
+// handler are ignored. First handler error stops processing and is
+// returned.
+func (r *SecurityEventReceiver) Receive(token []byte) error {
+	now := time.Now
+	if r.Now != nil {
+		now = r.Now
+	}
+	maxAge := r.MaxAge
+	if maxAge == 0 {
+		maxAge = DefaultSecurityEventMaxAge
+	}
+	skew := r.ClockSkew
+	if skew == 0 {
+		skew = time.Minute
+	}
+
+	opts := []DecodeOption{
+		WithClock(now),
+		WithType(SecurityEventType),
+		WithRequiredClaims("iss", "iat", "jti", "events"),
+		WithIssuer(r.Issuer),
+		WithClaimValidator("events", func(claims map[string]interface{}) error {
+			events, ok := claims["events"].(map[string]interface{})
+			if !ok {
+				return errors.New("not an object")
+			}
+			if len(events) == 0 {
+				return errors.New("no events")
+			}
+			for _, e := range events {
+				if _, ok := e.(map[string]interface{}); !ok {
+					return errors.New("event payload must be an object")
+				}
+			}
+			return nil
+		}),
+		WithClaimValidator("iat", func(claims map[string]interface{}) error {
+			iat, _ := claims["iat"].(float64)
+			age := now().Sub(time.Unix(int64(iat), 0))
+			if age > maxAge {
+				return errors.New("security event too old")
+			}
+			if age < -skew {
+				return errors.New("security event issued in the future")
+			}
+			return nil
+		}),
+	}
+	if r.Audience != "" {
+		opts = append(opts, WithAudience(r.Audience))
+	}
+	var set SecurityEventClaims
+	if err := DecodeClaims(token, r.Verifier, &set, opts...); err != nil {
+		return err
+	}
+	// token ID is kept only as long as the token would be accepted
+	if r.Store != nil {
+		if err := r.Store.Use(set.TokenID, time.Unix(set.IssuedAt, 0).Add(maxAge)); err != nil {
+			return err
+		}
+	}
+
+	types := make([]string, 0, len(set.Events))
+	for uri := range set.Events {
+		types = append(types, uri)
+	}
+	sort.Strings(types)
+
+	r.mu.RLock()
+	handlers := make([]SecurityEventHandler, len(types))
+	for i, uri := range types {
+		handlers[i] = r.handlers[uri]
+	}
+	r.mu.RUnlock()
+
+	for i, uri := range types {
+		if handlers[i] == nil {
+			continue
+		}
+		if err := handlers[i](set.Events[uri], &set); err != nil {
+			return fmt.Errorf("%s: %w", uri, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSecurityEvent(t *testing.T) {
+	const (
+		sessionRevoked    = "https://schemas.openid.net/secevent/caep/event-type/session-revoked"
+		credentialChange  = "https://schemas.openid.net/secevent/caep/event-type/credential-change"
+		unknownEventType  = "https://example.com/event-type/unknown"
+		transmitterIssuer = "https://transmitter.example.com"
+	)
+	signer := RSA256Signer(privRSA, "transmitter-key")
+
+	token, err := EncodeSecurityEvent(signer, transmitterIssuer, Audience{"https://receiver.example.com"}, map[string]interface{}{
+		sessionRevoked:   map[string]string{"reason": "logout"},
+		credentialChange: map[string]string{"credential_type": "password"},
+		unknownEventType: map[string]string{},
+	})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+
+	r := &SecurityEventReceiver{
+		Verifier: signer,
+		Issuer:   transmitterIssuer,
+		Audience: "https://receiver.example.com",
+		Store:    NewMemoryReplayStore(nil),
+	}
+	var got []string
+	r.Handle(sessionRevoked, func(event json.RawMessage, set *SecurityEventClaims) error {
+		var payload struct {
+			Reason string `json:"reason"`
+		}
+		if err := json.Unmarshal(event, &payload); err != nil {
+			return err
+		}
+		got = append(got, "session-revoked:"+payload.Reason)
+		return nil
+	})
+	r.Handle(credentialChange, func(event json.RawMessage, set *SecurityEventClaims) error {
+		if set.Issuer != transmitterIssuer {
+			t.Errorf("unexpected issuer: %q", set.Issuer)
+		}
+		got = append(got, "credential-change")
+		return nil
+	})
+
+	if err := r.Receive(token); err != nil {
+		t.Fatalf("cannot receive: %s", err)
+	}
+	if len(got) != 2 || got[0] != "credential-change" || got[1] != "session-revoked:logout" {
+		t.Fatalf("unexpected handled events: %v", got)
+	}
+	if err := r.Receive(token); err != ErrReplayed {
+		t.Fatalf("want ErrReplayed, got %v", err)
+	}
+
+	// plain JWT must not be accepted as security event
+	jwt, err := Encode(signer, &SecurityEventClaims{Issuer: transmitterIssuer, IssuedAt: 1, TokenID: "x"})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+	if err := r.Receive(jwt); err != ErrInvalidType {
+		t.Fatalf("want ErrInvalidType, got %v", err)
+	}
+
+	errHandler := errors.New("handler failed")
+	r.Handle(sessionRevoked, func(json.RawMessage, *SecurityEventClaims) error {
+		return errHandler
+	})
+	token, err = EncodeSecurityEvent(signer, transmitterIssuer, Audience{"https://receiver.example.com"}, map[string]interface{}{
+		sessionRevoked: map[string]string{},
+	})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+	if err := r.Receive(token); !errors.Is(err, errHandler) {
+		t.Fatalf("want handler error, got %v", err)
+	}
+}
+
+func TestSecurityEventMaxAge(t *testing.T) {
+	signer := RSA256Signer(privRSA, "transmitter-key")
+	now := time.Now()
+	store := NewMemoryReplayStore(func() time.Time { return now })
+	r := &SecurityEventReceiver{
+		Verifier: signer,
+		Issuer:   "https://transmitter.example.com",
+		Store:    store,
+		MaxAge:   time.Hour,
+		Now:      func() time.Time { return now },
+	}
+
+	encode := func(iat time.Time, jti string) []byte {
+		t.Helper()
+		token, err := Encode(signer, &SecurityEventClaims{
+			Issuer:   r.Issuer,
+			IssuedAt: iat.Unix(),
+			TokenID:  jti,
+			Events:   map[string]json.RawMessage{"https://example.com/event": json.RawMessage(`{}`)},
+		}, EncodeWithType(SecurityEventType))
+		if err != nil {
+			t.Fatalf("cannot encode: %s", err)
+		}
+		return token
+	}
+
+	var cerr *ClaimError
+	if err := r.Receive(encode(now.Add(-2*time.Hour), "old")); !errors.As(err, &cerr) || cerr.Claim != "iat" {
+		t.Fatalf("want iat claim error, got %v", err)
+	}
+
+	// tokens from the future are accepted only within clock skew
+	if err := r.Receive(encode(now.Add(2*time.Minute), "future")); !errors.As(err, &cerr) || cerr.Claim != "iat" {
+		t.Fatalf("want iat claim error, got %v", err)
+	}
+	if err := r.Receive(encode(now.Add(30*time.Second), "skewed")); err != nil {
+		t.Fatalf("cannot receive token within clock skew: %s", err)
+	}
+
+	token := encode(now.Add(-30*time.Minute), "recent")
+	if err := r.Receive(token); err != nil {
+		t.Fatalf("cannot receive: %s", err)
+	}
+	if err := r.Receive(token); err != ErrReplayed {
+		t.Fatalf("want ErrReplayed, got %v", err)
+	}
+
+	// once the token is too old to be accepted, its ID is no longer needed
+	now = now.Add(31 * time.Minute)
+	if err := store.Use("recent", now.Add(time.Hour)); err != nil {
+		t.Fatalf("token ID not released: %s", err)
+	}
+	if err := r.Receive(token); !errors.As(err, &cerr) || cerr.Claim != "iat" {
+		t.Fatalf("want iat claim error, got %v", err)
+	}
+}
@@ -0,0 +1,341 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// SigningHandler is HTTP handler exposing signer to other services, so that
+// private keys can be kept in a single place. Use RemoteSigner to call it.
+//
+// Handler serves following endpoints, relative to the path it is mounted at:
+//
+//	GET  /key   returns algorithm and key ID of the active key
+//	POST /sign  signs data using the active key
+//	GET  /jwks  returns public keys as JSON Web Key Set
+//
+// Only the sign endpoint requires authorization.
+type SigningHandler struct {
+	// Signer is used to sign data. If it is RotatingSigner, its whole
+	// verification set is published as key set.
+	Signer Signer
+
+	// Authorize returns true if request is allowed to sign data. It is
+	// required, see BearerAuth.
+	Authorize func(*http.Request) bool
+}
+
+// BearerAuth returns function authorizing requests carrying given bearer
+// token in Authorization header.
+func BearerAuth(token string) func(*http.Request) bool {
+	want := []byte("Bearer " + token)
+	return func(r *http.Request) bool {
+		got := []byte(r.Header.Get("Authorization"))
+		return subtle.ConstantTimeCompare(got, want) == 1
+	}
+}
+
+type signingKey struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+type signRequest struct {
+	// Input is base64 encoded JWS signing input. Signing key is the one
+	// declared by its header.
+	Input string `json:"input"`
+}
+
+type signResponse struct {
+	Signature string `json:"signature"`
+}
+
+func (h *SigningHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch path.Base(r.URL.Path) {
+	case "key":
+		if r.Method != "GET" {
+			writeServiceError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		sig, err := h.current()
+		if err != nil {
+			writeServiceError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		writeServiceJSON(w, signingKeyOf(sig))
+	case "jwks":
+		if r.Method != "GET" {
+			writeServiceError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		keys, err := h.keys()
+		if err != nil {
+			writeServiceError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeServiceJSON(w, keys)
+	case "sign":
+		if r.Method != "POST" {
+			writeServiceError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if h.Authorize == nil || !h.Authorize(r) {
+			writeServiceError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		h.sign(w, r)
+	default:
+		writeServiceError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (h *SigningHandler) sign(w http.ResponseWriter, r *http.Request) {
+	var req signRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRemoteDocument))
+	if err := dec.Decode(&req); err != nil {
+		writeServiceError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	input, err := decodeSegment([]byte(req.Input), true)
+	if err != nil {
+		writeServiceError(w, http.StatusBadRequest, "invalid input")
+		return
+	}
+	declared, err := signingInputKey(input)
+	if err != nil {
+		writeServiceError(w, http.StatusBadRequest, "invalid input")
+		return
+	}
+
+	sig, err := h.current()
+	if err != nil {
+		writeServiceError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	// client has already built token header, so signing key must be the
+	// one declared there
+	if signingKeyOf(sig) != declared {
+		writeServiceError(w, http.StatusConflict, ErrSigningKeyChanged.Error())
+		return
+	}
+
+	var signature []byte
+	if s, ok := sig.(ContextSigner); ok {
+		signature, err = s.SignContext(r.Context(), input)
+	} else {
+		signature, err = sig.Sign(input)
+	}
+	if err != nil {
+		writeServiceError(w, http.StatusInternalServerError, "cannot sign")
+		return
+	}
+	encoded, _ := encode(signature)
+	writeServiceJSON(w, signResponse{Signature: string(encoded)})
+}
+
+// current returns signer of the active key.
+func (h *SigningHandler) current() (Signer, error) {
+	if s, ok := h.Signer.(activeSigner); ok {
+		return s.Current()
+	}
+	return h.Signer, nil
+}
+
+// keys returns public keys of the signer.
+func (h *SigningHandler) keys() (*JWKSet, error) {
+	if r, ok := h.Signer.(*RotatingSigner); ok {
+		return r.JWKS(), nil
+	}
+	jwk, err := PublicJWK(h.Signer)
+	if err != nil {
+		return nil, err
+	}
+	return &JWKSet{Keys: []JWK{jwk}}, nil
+}
+
+func signingKeyOf(sig Signer) signingKey {
+	key := signingKey{Algorithm: sig.Algorithm()}
+	if s, ok := sig.(namedKeyHolder); ok {
+		key.KeyID = s.KeyID()
+	}
+	return key
+}
+
+// signingInputKey returns signing key declared by the header of given JWS
+// signing input.
+func signingInputKey(input []byte) (signingKey, error) {
+	var key signingKey
+	i := bytes.IndexByte(input, '.')
+	if i < 0 || bytes.IndexByte(input[i+1:], '.') >= 0 {
+		return key, ErrMalformedToken
+	}
+	header, err := decodeSegment(input[:i], true)
+	if err != nil {
+		return key, &SegmentError{Segment: "header", Err: err}
+	}
+	if err := json.Unmarshal(header, &key); err != nil {
+		return key, fmt.Errorf("cannot JSON decode header: %s", err)
+	}
+	if key.Algorithm == "" {
+		return key, ErrMalformedToken
+	}
+	return key, nil
+}
+
+func writeServiceJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeServiceError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// ErrSigningKeyChanged is returned by RemoteSigner when signing service
+// active key changed after token header was created. Signing should be
+// retried.
+var ErrSigningKeyChanged = errors.New("signing key changed")
+
+// RemoteSigner is Signer using SigningHandler served at remote URL, so that
+// Encode can be used without access to the private key. Signatures are
+// verified using public keys published by the service, but only for the
+// active key. To verify tokens signed before key rotation, use RemoteKeySet
+// with the service "/jwks" endpoint.
+type RemoteSigner struct {
+	url    string
+	client *http.Client
+	token  string
+	keys   *RemoteKeySet
+
+	mu  sync.RWMutex
+	key signingKey
+}
+
+var _ ContextSigner = (*RemoteSigner)(nil)
+
+// NewRemoteSigner returns signer using signing service at given URL, that
+// SigningHandler is mounted at. Active key is fetched immediately.
+//
+// client is optional (can be nil) HTTP client, http.DefaultClient is used
+// otherwise. token is sent as bearer token with signing requests, see
+// BearerAuth.
+func NewRemoteSigner(ctx context.Context, url string, client *http.Client, token string) (*RemoteSigner, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	url = strings.TrimRight(url, "/")
+	s := &RemoteSigner{
+		url:    url,
+		client: client,
+		token:  token,
+		keys:   NewRemoteKeySet(url+"/jwks", client),
+	}
+	if err := s.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Refresh fetches active key of the signing service. It is done
+// automatically when signing fails with ErrSigningKeyChanged.
+func (s *RemoteSigner) Refresh(ctx context.Context) error {
+	var key signingKey
+	if err := getJSON(ctx, s.client, s.url+"/key", &key); err != nil {
+		return fmt.Errorf("cannot fetch signing key: %w", err)
+	}
+	if key.Algorithm == "" || key.Algorithm == "none" {
+		return ErrInvalidSigner
+	}
+	s.mu.Lock()
+	s.key = key
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *RemoteSigner) current() signingKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.key
+}
+
+// Algorithm returns algorithm of the active key.
+func (s *RemoteSigner) Algorithm() string {
+	return s.current().Algorithm
+}
+
+// KeyID returns ID of the active key.
+func (s *RemoteSigner) KeyID() string {
+	return s.current().KeyID
+}
+
+// Sign returns signature computed by signing service.
+func (s *RemoteSigner) Sign(data []byte) ([]byte, error) {
+	return s.SignContext(context.Background(), data)
+}
+
+// SignContext returns signature computed by signing service, using given
+// context for the request. Data must be JWS signing input, because signing
+// service uses key declared by its header.
+func (s *RemoteSigner) SignContext(ctx context.Context, data []byte) ([]byte, error) {
+	declared, err := signingInputKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing input: %w", err)
+	}
+	input, _ := encode(data)
+	body, err := json.Marshal(signRequest{Input: string(input)})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", s.url+"/sign", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	var resp signResponse
+	if err := doJSON(s.client, req, &resp); err != nil {
+		var serr *statusError
+		if errors.As(err, &serr) && serr.code == http.StatusConflict {
+			// active key might have been already refreshed by other
+			// caller after data header was created
+			if s.current() == declared {
+				if err := s.Refresh(ctx); err != nil {
+					return nil, err
+				}
+			}
+			return nil, ErrSigningKeyChanged
+		}
+		return nil, err
+	}
+	return decodeSegment([]byte(resp.Signature), true)
+}
+
+// Verify returns nil if signature was computed by the active key, using
+// public keys published by signing service.
+func (s *RemoteSigner) Verify(signature, data []byte) error {
+	return s.VerifyContext(context.Background(), signature, data)
+}
+
+// VerifyContext is like Verify, but given context is used if public keys
+// must be fetched.
+func (s *RemoteSigner) VerifyContext(ctx context.Context, signature, data []byte) error {
+	key := s.current()
+	v, err := s.keys.LookupContext(ctx, key.Algorithm, key.KeyID)
+	if err != nil {
+		return err
+	}
+	return v.Verify(signature, data)
+}
@@ -0,0 +1,172 @@
+package jwt
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	now := time.Now()
+	rot := NewRotatingSigner(time.Hour, func() time.Time { return now })
+	if err := rot.Add(RSA256Signer(privRSA2048, "first"), now.Add(-time.Hour)); err != nil {
+		t.Fatalf("cannot add key: %s", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/signer/", http.StripPrefix("/signer", &SigningHandler{
+		Signer:    rot,
+		Authorize: BearerAuth("service-token"),
+	}))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx := context.Background()
+	sig, err := NewRemoteSigner(ctx, srv.URL+"/signer/", srv.Client(), "service-token")
+	if err != nil {
+		t.Fatalf("cannot create remote signer: %s", err)
+	}
+	if sig.Algorithm() != "RS256" || sig.KeyID() != "first" {
+		t.Fatalf("unexpected key: %s %s", sig.Algorithm(), sig.KeyID())
+	}
+
+	keys := NewRemoteKeySet(srv.URL+"/signer/jwks", srv.Client())
+	token, err := Encode(sig, map[string]string{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+	var claims map[string]string
+	if err := DecodeClaims(token, keys, &claims); err != nil {
+		t.Fatalf("cannot decode using key set: %s", err)
+	}
+	if err := DecodeClaims(token, sig, nil); err != nil {
+		t.Fatalf("cannot decode using remote signer: %s", err)
+	}
+	if err := DecodeClaims(token, rot, nil); err != nil {
+		t.Fatalf("cannot decode using local signer: %s", err)
+	}
+
+	// active key rotated after remote signer fetched it
+	now = now.Add(time.Minute)
+	if err := rot.Add(RSA256Signer(privRSA2048, "second"), now); err != nil {
+		t.Fatalf("cannot add key: %s", err)
+	}
+	if _, err := Encode(sig, map[string]string{"sub": "alice"}); !errors.Is(err, ErrSigningKeyChanged) {
+		t.Fatalf("want ErrSigningKeyChanged, got %v", err)
+	}
+	token, err = Encode(sig, map[string]string{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("cannot encode after refresh: %s", err)
+	}
+	if tok, _ := ParseUnverified(token); tok.KeyID() != "second" {
+		t.Fatalf("want token signed with second key, got %q", tok.KeyID())
+	}
+	keys.MinRefreshInterval = 0
+	if err := DecodeClaims(token, keys, nil); err != nil {
+		t.Fatalf("cannot decode after rotation: %s", err)
+	}
+
+	// key must be taken from the header, even if signer was refreshed
+	// after the header was created
+	if _, err := sig.Sign(signingInput(t, "RS256", "first")); !errors.Is(err, ErrSigningKeyChanged) {
+		t.Fatalf("want ErrSigningKeyChanged for stale header, got %v", err)
+	}
+	if sig.KeyID() != "second" {
+		t.Fatalf("want second key kept, got %q", sig.KeyID())
+	}
+	if _, err := sig.Sign([]byte("data")); err == nil {
+		t.Fatal("want error for data that is not signing input")
+	}
+
+	// context errors are kept, so that callers can tell cancellation apart
+	// from service failures
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := sig.Refresh(canceled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled, got %v", err)
+	}
+	if sig.KeyID() != "second" {
+		t.Fatalf("want second key kept after failed refresh, got %q", sig.KeyID())
+	}
+
+	// signing requires authorization
+	for _, token := range []string{"", "other-token"} {
+		unauthorized, err := NewRemoteSigner(ctx, srv.URL+"/signer", srv.Client(), token)
+		if err != nil {
+			t.Fatalf("cannot create remote signer: %s", err)
+		}
+		_, err = unauthorized.Sign(signingInput(t, "RS256", "second"))
+		var serr *statusError
+		if !errors.As(err, &serr) || serr.code != http.StatusUnauthorized {
+			t.Fatalf("%q: want unauthorized error, got %v", token, err)
+		}
+	}
+}
+
+// signingInput returns JWS signing input with header declaring given key.
+func signingInput(t *testing.T, alg, keyID string) []byte {
+	t.Helper()
+	header, err := json.Marshal(signingKey{Algorithm: alg, KeyID: keyID})
+	if err != nil {
+		t.Fatalf("cannot encode header: %s", err)
+	}
+	return []byte(base64.RawURLEncoding.EncodeToString(header) + ".e30")
+}
+
+func TestSigningHandler(t *testing.T) {
+	h := &SigningHandler{
+		Signer:    HMAC256([]byte("secret"), "hmac"),
+		Authorize: BearerAuth("service-token"),
+	}
+	input := func(data []byte) string {
+		body, _ := json.Marshal(signRequest{Input: base64.RawURLEncoding.EncodeToString(data)})
+		return string(body)
+	}
+
+	cases := map[string]struct {
+		method   string
+		path     string
+		body     string
+		wantCode int
+	}{
+		"sign":            {method: "POST", path: "/sign", body: input(signingInput(t, "HS256", "hmac")), wantCode: http.StatusOK},
+		"sign-wrong-kid":  {method: "POST", path: "/sign", body: input(signingInput(t, "HS256", "other")), wantCode: http.StatusConflict},
+		"sign-wrong-alg":  {method: "POST", path: "/sign", body: input(signingInput(t, "HS512", "hmac")), wantCode: http.StatusConflict},
+		"sign-no-header":  {method: "POST", path: "/sign", body: input([]byte("data")), wantCode: http.StatusBadRequest},
+		"sign-bad-base64": {method: "POST", path: "/sign", body: `{"input": "!"}`, wantCode: http.StatusBadRequest},
+		"key":             {method: "GET", path: "/key", wantCode: http.StatusOK},
+		"no-public-key":   {method: "GET", path: "/jwks", wantCode: http.StatusNotFound},
+		"unauthorized":    {method: "POST", path: "/sign", wantCode: http.StatusUnauthorized},
+		"sign-not-post":   {method: "GET", path: "/sign", wantCode: http.StatusMethodNotAllowed},
+		"unknown-path":    {method: "GET", path: "/other", wantCode: http.StatusNotFound},
+		"key-not-allowed": {method: "DELETE", path: "/key", wantCode: http.StatusMethodNotAllowed},
+	}
+
+	for tname, tc := range cases {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		if tc.body != "" {
+			r.Header.Set("Authorization", "Bearer service-token")
+		}
+		h.ServeHTTP(w, r)
+		if w.Code != tc.wantCode {
+			t.Errorf("%s: want %d, got %d", tname, tc.wantCode, w.Code)
+		}
+	}
+
+	// signing is never allowed without authorizer
+	h.Authorize = nil
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/sign", strings.NewReader(cases["sign"].body))
+	r.Header.Set("Authorization", "Bearer service-token")
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("no-authorizer: want %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
@@ -0,0 +1,71 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+)
+
+// ErrWeakKey is returned when key does not meet minimum strength
+// requirements, see AllowWeakKeys.
+var ErrWeakKey = errors.New("weak key")
+
+// MinRSAKeySize is the minimum accepted RSA modulus size in bits, as
+// required by RFC 7518, section 3.3.
+const MinRSAKeySize = 2048
+
+// KeyOption configures key validation done by constructors returning
+// errors, like NewHMAC256 or NewRSA256Signer.
+type KeyOption func(*keyOptions)
+
+type keyOptions struct {
+	allowWeak bool
+}
+
+// AllowWeakKeys returns option that disables key strength validation. It
+// should be used only for interoperability with legacy systems, that cannot
+// rotate their keys.
+func AllowWeakKeys() KeyOption {
+	return func(o *keyOptions) {
+		o.allowWeak = true
+	}
+}
+
+func newKeyOptions(opts []KeyOption) keyOptions {
+	var conf keyOptions
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	return conf
+}
+
+// checkHMACKey returns ErrWeakKey if HMAC key is shorter than the output of
+// used hash function, as required by RFC 7518, section 3.2.
+func checkHMACKey(key []byte, hash crypto.Hash, opts []KeyOption) error {
+	if newKeyOptions(opts).allowWeak {
+		return nil
+	}
+	if len(key) < hash.Size() {
+		return fmt.Errorf("%w: HMAC key must be at least %d bytes long", ErrWeakKey, hash.Size())
+	}
+	return nil
+}
+
+// checkRSAKey returns ErrWeakKey if RSA key is shorter than MinRSAKeySize or
+// its public exponent is invalid.
+func checkRSAKey(key *rsa.PublicKey, opts []KeyOption) error {
+	if key == nil || key.N == nil {
+		return ErrUnsupportedKey
+	}
+	if key.E < 3 || key.E%2 == 0 {
+		return fmt.Errorf("%w: invalid RSA public exponent", ErrWeakKey)
+	}
+	if newKeyOptions(opts).allowWeak {
+		return nil
+	}
+	if key.N.BitLen() < MinRSAKeySize {
+		return fmt.Errorf("%w: RSA key must be at least %d bits long", ErrWeakKey, MinRSAKeySize)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestKeyStrength(t *testing.T) {
+	cases := map[string]struct {
+		create  func(opts ...KeyOption) (Verifier, error)
+		wantErr error
+	}{
+		"hmac256-ok": {
+			create: func(opts ...KeyOption) (Verifier, error) {
+				return NewHMAC256(bytes.Repeat([]byte("k"), 32), "", opts...)
+			},
+		},
+		"hmac256-short": {
+			create: func(opts ...KeyOption) (Verifier, error) {
+				return NewHMAC256([]byte("abc"), "", opts...)
+			},
+			wantErr: ErrWeakKey,
+		},
+		"hmac256-empty": {
+			create: func(opts ...KeyOption) (Verifier, error) {
+				return NewHMAC256(nil, "", opts...)
+			},
+			wantErr: ErrWeakKey,
+		},
+		"hmac384-short": {
+			create: func(opts ...KeyOption) (Verifier, error) {
+				return NewHMAC384(bytes.Repeat([]byte("k"), 32), "", opts...)
+			},
+			wantErr: ErrWeakKey,
+		},
+		"hmac512-ok": {
+			create: func(opts ...KeyOption) (Verifier, error) {
+				return NewHMAC512(bytes.Repeat([]byte("k"), 64), "", opts...)
+			},
+		},
+		"rsa256-signer-ok": {
+			create: func(opts ...KeyOption) (Verifier, error) {
+				return NewRSA256Signer(privRSA2048, "", opts...)
+			},
+		},
+		"rsa256-signer-short": {
+			create: func(opts ...KeyOption) (Verifier, error) {
+				return NewRSA256Signer(privRSA, "", opts...)
+			},
+			wantErr: ErrWeakKey,
+		},
+		"rsa384-verifier-short": {
+			create: func(opts ...KeyOption) (Verifier, error) {
+				return NewRSA384Verifier(&privRSA.PublicKey, opts...)
+			},
+			wantErr: ErrWeakKey,
+		},
+		"rsa512-verifier-ok": {
+			create: func(opts ...KeyOption) (Verifier, error) {
+				return NewRSA512Verifier(&privRSA2048.PublicKey, opts...)
+			},
+		},
+	}
+
+	for tname, tc := range cases {
+		v, err := tc.create()
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: want %v, got %v", tname, tc.wantErr, err)
+			continue
+		}
+		if err == nil && v == nil {
+			t.Errorf("%s: nil verifier returned", tname)
+		}
+		// weak keys are accepted only when explicitly allowed
+		if _, err := tc.create(AllowWeakKeys()); err != nil {
+			t.Errorf("%s: weak keys allowed: %s", tname, err)
+		}
+	}
+}
+
+func TestKeyStrengthInvalidRSAExponent(t *testing.T) {
+	key := &rsa.PublicKey{N: privRSA2048.N, E: 4}
+	if _, err := NewRSA256Verifier(key, AllowWeakKeys()); !errors.Is(err, ErrWeakKey) {
+		t.Fatalf("want ErrWeakKey, got %v", err)
+	}
+	if _, err := NewRSA256Verifier(&rsa.PublicKey{E: 65537}); err != ErrUnsupportedKey {
+		t.Fatalf("want ErrUnsupportedKey, got %v", err)
+	}
+	small := &rsa.PublicKey{N: big.NewInt(3233), E: 17}
+	if _, err := NewRSA256Verifier(small); !errors.Is(err, ErrWeakKey) {
+		t.Fatalf("want ErrWeakKey, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrInvalidClaimsType is returned by Decode and EncodeClaims if claims type
+// cannot be represented as JSON object, for example because it is a slice,
+// string or number.
+var ErrInvalidClaimsType = errors.New("invalid claims type")
+
+// Header holds registered JOSE header parameters of verified token.
+type Header struct {
+	Algorithm   string `json:"alg"`
+	KeyID       string `json:"kid,omitempty"`
+	Type        string `json:"typ,omitempty"`
+	ContentType string `json:"cty,omitempty"`
+}
+
+// Decode is like DecodeClaims, but returns claims as value of type T, that
+// must be a structure, a map with string keys, a pointer to either of them or
+// a type implementing json.Unmarshaler. If T is an interface type, claims
+// are decoded as map[string]interface{}.
+//
+// Claims and header are returned only if token is valid, otherwise zero
+// values are returned together with the error.
+func Decode[T any](token []byte, v Verifier, opts ...DecodeOption) (T, Header, error) {
+	return DecodeContext[T](context.Background(), token, v, opts...)
+}
+
+// DecodeContext is like Decode, but given context is used as described by
+// DecodeClaimsContext.
+func DecodeContext[T any](ctx context.Context, token []byte, v Verifier, opts ...DecodeOption) (T, Header, error) {
+	var claims T
+	if err := checkClaimsType(reflect.TypeOf(&claims).Elem(), unmarshalerType); err != nil {
+		return claims, Header{}, err
+	}
+	if err := DecodeClaimsContext(ctx, token, v, &claims, opts...); err != nil {
+		var zero T
+		return zero, Header{}, err
+	}
+	// token is valid, so header is known to be a well formed JSON object
+	var header Header
+	if err := DecodeHeader(token, &header); err != nil {
+		var zero T
+		return zero, Header{}, fmt.Errorf("cannot decode header: %s", err)
+	}
+	return claims, header, nil
+}
+
+// EncodeClaims is like Encode, but accepts only claims of type that can be
+// serialized as JSON object, as described by Decode. Types implementing
+// json.Marshaler are accepted as well, but their output is not checked until
+// encoding.
+func EncodeClaims[T any](sig Signer, claims T, opts ...EncodeOption) ([]byte, error) {
+	return EncodeClaimsContext(context.Background(), sig, claims, opts...)
+}
+
+// EncodeClaimsContext is like EncodeClaims, but given context is used as
+// described by EncodeContext.
+func EncodeClaimsContext[T any](ctx context.Context, sig Signer, claims T, opts ...EncodeOption) ([]byte, error) {
+	t := reflect.TypeOf(&claims).Elem()
+	if t.Kind() == reflect.Interface {
+		// dynamic type of interface value is all that can be checked
+		t = reflect.TypeOf(claims)
+		if t == nil {
+			return nil, fmt.Errorf("%w: nil", ErrInvalidClaimsType)
+		}
+	}
+	if err := checkClaimsType(t, marshalerType); err != nil {
+		return nil, err
+	}
+	return EncodeContext(ctx, sig, claims, opts...)
+}
+
+var (
+	marshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// checkClaimsType returns ErrInvalidClaimsType if values of given type are
+// not serialized as JSON object. Types implementing given JSON interface,
+// directly or through pointer, are accepted.
+func checkClaimsType(t reflect.Type, custom reflect.Type) error {
+	for {
+		if t.Implements(custom) || reflect.PointerTo(t).Implements(custom) {
+			return nil
+		}
+		if t.Kind() != reflect.Pointer {
+			break
+		}
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return nil
+	case reflect.Interface:
+		// JSON objects can be decoded only into empty interface
+		if t.NumMethod() == 0 {
+			return nil
+		}
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidClaimsType, t)
+}
@@ -0,0 +1,128 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type typedClaims struct {
+	Subject string   `json:"sub"`
+	Admin   bool     `json:"admin"`
+	Scopes  []string `json:"scopes"`
+}
+
+func TestDecodeTyped(t *testing.T) {
+	sig := HMAC256([]byte("top secret 7720132"), "key")
+	token, err := EncodeClaims(sig, typedClaims{Subject: "alice", Admin: true, Scopes: []string{"read"}},
+		EncodeWithHeader("cty", "example"))
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+
+	claims, header, err := Decode[typedClaims](token, sig)
+	if err != nil {
+		t.Fatalf("cannot decode: %s", err)
+	}
+	if claims.Subject != "alice" || !claims.Admin || len(claims.Scopes) != 1 {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+	want := Header{Algorithm: "HS256", KeyID: "key", Type: "JWT", ContentType: "example"}
+	if header != want {
+		t.Errorf("want %+v header, got %+v", want, header)
+	}
+
+	ptr, _, err := Decode[*typedClaims](token, sig)
+	if err != nil || ptr == nil || ptr.Subject != "alice" {
+		t.Errorf("cannot decode into pointer: %+v, %v", ptr, err)
+	}
+	m, _, err := Decode[map[string]interface{}](token, sig)
+	if err != nil || m["sub"] != "alice" {
+		t.Errorf("cannot decode into map: %v, %v", m, err)
+	}
+	raw, _, err := Decode[json.RawMessage](token, sig)
+	if err != nil || len(raw) == 0 {
+		t.Errorf("cannot decode into raw message: %s, %v", raw, err)
+	}
+	v, _, err := Decode[interface{}](token, sig)
+	if _, ok := v.(map[string]interface{}); err != nil || !ok {
+		t.Errorf("cannot decode into interface: %T, %v", v, err)
+	}
+}
+
+func TestDecodeTypedInvalid(t *testing.T) {
+	sig := HMAC256([]byte("top secret 7720132"), "key")
+	expired, err := EncodeClaims(sig, map[string]interface{}{
+		"sub": "alice",
+		"exp": time.Now().Add(-time.Minute).Unix(),
+	})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+
+	claims, header, err := Decode[typedClaims](expired, sig)
+	if err != ErrExpired {
+		t.Fatalf("want %v, got %v", ErrExpired, err)
+	}
+	if claims.Subject != "" || header != (Header{}) {
+		t.Errorf("want zero values for invalid token, got %+v, %+v", claims, header)
+	}
+
+	if _, _, err := Decode[typedClaims](expired, HMAC256([]byte("other secret 4491201"), "key")); err != ErrInvalidSignature {
+		t.Errorf("want %v, got %v", ErrInvalidSignature, err)
+	}
+}
+
+func TestInvalidClaimsType(t *testing.T) {
+	sig := HMAC256([]byte("top secret 7720132"), "")
+	token, err := Encode(sig, map[string]string{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("cannot encode: %s", err)
+	}
+
+	cases := map[string]struct {
+		decode func() error
+		encode func() error
+	}{
+		"string": {
+			decode: func() error { _, _, err := Decode[string](token, sig); return err },
+			encode: func() error { _, err := EncodeClaims(sig, "alice"); return err },
+		},
+		"slice": {
+			decode: func() error { _, _, err := Decode[[]string](token, sig); return err },
+			encode: func() error { _, err := EncodeClaims(sig, []string{"alice"}); return err },
+		},
+		"int-map": {
+			decode: func() error { _, _, err := Decode[map[int]string](token, sig); return err },
+			encode: func() error { _, err := EncodeClaims(sig, map[int]string{1: "alice"}); return err },
+		},
+		"pointer-to-number": {
+			decode: func() error { _, _, err := Decode[*int](token, sig); return err },
+			encode: func() error { n := 1; _, err := EncodeClaims(sig, &n); return err },
+		},
+		"non-empty-interface": {
+			decode: func() error { _, _, err := Decode[error](token, sig); return err },
+		},
+		"interface-holding-number": {
+			encode: func() error { _, err := EncodeClaims[interface{}](sig, 42); return err },
+		},
+		"nil-interface": {
+			encode: func() error { _, err := EncodeClaims[interface{}](sig, nil); return err },
+		},
+	}
+
+	for tname, tc := range cases {
+		if tc.decode != nil {
+			if err := tc.decode(); !errors.Is(err, ErrInvalidClaimsType) {
+				t.Errorf("%s: want decode error %v, got %v", tname, ErrInvalidClaimsType, err)
+			}
+		}
+		if tc.encode == nil {
+			continue
+		}
+		if err := tc.encode(); !errors.Is(err, ErrInvalidClaimsType) {
+			t.Errorf("%s: want encode error %v, got %v", tname, ErrInvalidClaimsType, err)
+		}
+	}
+}